@@ -0,0 +1,152 @@
+// routes : ルート定義ファイルを読み込み、ルーティングテーブルの確認を行うコマンド
+//
+//	routes [-f file] list                 ルートパスの一覧を表示する
+//	routes [-f file] match METHOD PATH    パスに該当するアクションと引数を表示する
+//	routes [-f file] lint                 ルートパスの問題を検出する
+//
+// ルート定義ファイルは RouteTable.Load で読み込み可能な形式とする。-f を省略した場合は標準入力から読み込む
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ochipin/router"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run : コマンドを実行し、終了コードを返却する
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("routes", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	file := flags.String("f", "", "routes file (default stdin)")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: routes [-f file] list | match METHOD PATH | lint")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	// ルート定義を読み込む
+	in := stdin
+	if *file != "" && *file != "-" {
+		fp, err := os.Open(*file)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		defer fp.Close()
+		in = fp
+	}
+	rt := router.New()
+	if err := rt.Load(in); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	cmd := flags.Args()
+	if len(cmd) == 0 {
+		cmd = []string{"list"}
+	}
+	switch {
+	case cmd[0] == "list" && len(cmd) == 1:
+		list(rt, stdout)
+		return 0
+	case cmd[0] == "match" && len(cmd) == 3:
+		return match(rt, cmd[1], cmd[2], stdout, stderr)
+	case cmd[0] == "lint" && len(cmd) == 1:
+		return lint(rt, stdout)
+	}
+	flags.Usage()
+	return 2
+}
+
+// methodOrder : 一覧表示時のメソッドの並び順
+var methodOrder = map[string]int{"GET": 1, "HEAD": 2, "POST": 3, "PUT": 4, "PATCH": 5, "DELETE": 6, "OPTIONS": 7}
+
+// list : ルートパスと正規表現の一覧を、パス、メソッドの順に整列して表示する
+func list(rt *router.RouteTable, w io.Writer) {
+	table := rt.TableList()
+	routes := table["ROUTER"]
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i][1] != routes[j][1] {
+			return routes[i][1] < routes[j][1]
+		}
+		a, b := methodOrder[routes[i][0]], methodOrder[routes[j][0]]
+		if a != b {
+			return a < b
+		}
+		return routes[i][0] < routes[j][0]
+	})
+	regex := table["REGEXP"]
+	sort.Slice(regex, func(i, j int) bool { return regex[i][0] < regex[j][0] })
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATH\tACTION")
+	for _, r := range routes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r[0], r[1], r[2])
+	}
+	tw.Flush()
+	if len(regex) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "REGEXP\tPATTERN")
+	for _, r := range regex {
+		fmt.Fprintf(tw, "%s\t%s\n", r[0], r[1])
+	}
+	tw.Flush()
+}
+
+// match : パスに該当するアクションと、アクションへ渡す引数を表示する
+// 該当するアクションが存在しない場合は、候補となるルートをエラーメッセージに含める
+func match(rt *router.RouteTable, method, path string, stdout, stderr io.Writer) int {
+	rt.Options.Suggest = true
+	r, err := rt.CreateDry()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	action, args, err := r.Caller(method, path)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		if v, ok := err.(*router.NotRoutes); ok && v.Redirect != "" {
+			fmt.Fprintf(stderr, "redirect to '%s'\n", v.Redirect)
+		}
+		return 1
+	}
+	if rd, ok := action.(*router.Redirect); ok {
+		fmt.Fprintf(stdout, "%s %s -> redirect %d %s\n", method, path, rd.Status, rd.Location)
+		return 0
+	}
+	ctlname, actname := action.Name()
+	values := make([]string, len(args))
+	for i, arg := range args {
+		values[i] = fmt.Sprintf("%#v", arg.Interface())
+	}
+	fmt.Fprintf(stdout, "%s %s -> %s.%s(%s)\n", method, path, ctlname, actname, strings.Join(values, ", "))
+	return 0
+}
+
+// lint : ルートパスの問題を表示する。問題がある場合は 1 を返却する
+func lint(rt *router.RouteTable, w io.Writer) int {
+	errs := rt.Lint()
+	for _, err := range errs {
+		fmt.Fprintln(w, err)
+	}
+	if len(errs) != 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const routes = `
+REGEXP id ([0-9]+)
+POST   /users                   Users.Create
+GET    /users                   Users.Index
+GET    /users/:id               Users.Show
+GET    /archive(/:year<int>)    Archive.Index
+GET    /u/:id                   REDIRECT 301 /users/:id
+`
+
+func runString(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(routes), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func Test__ROUTES_LIST(t *testing.T) {
+	code, out, _ := runString("list")
+	want := `METHOD  PATH                   ACTION
+GET     /archive(/:year<int>)  Archive.Index
+GET     /u/:id                 REDIRECT 301 /users/:id
+GET     /users                 Users.Index
+POST    /users                 Users.Create
+GET     /users/:id             Users.Show
+
+REGEXP  PATTERN
+:id     ([0-9]+)
+`
+	if code != 0 || out != want {
+		t.Fatal("list: Error", code, out)
+	}
+}
+
+func Test__ROUTES_MATCH(t *testing.T) {
+	var tests = []struct {
+		method, path, result string
+	}{
+		{"GET", "/users/42", `GET /users/42 -> Users.Show("42")` + "\n"},
+		{"GET", "/archive/2024", `GET /archive/2024 -> Archive.Index(2024)` + "\n"},
+		{"POST", "/users", `POST /users -> Users.Create()` + "\n"},
+		{"GET", "/u/42", `GET /u/42 -> redirect 301 /users/42` + "\n"},
+	}
+	for _, test := range tests {
+		code, out, errout := runString("match", test.method, test.path)
+		if code != 0 || out != test.result {
+			t.Fatal("match: Error", test.path, out, errout)
+		}
+	}
+	if code, _, errout := runString("match", "DELETE", "/users/42"); code != 1 || errout == "" {
+		t.Fatal("match: Error", code, errout)
+	}
+	// 該当しない場合は、候補となるルートを表示する
+	if code, _, errout := runString("match", "GET", "/usrs"); code != 1 || !strings.Contains(errout, "did you mean '[GET]: /users'") {
+		t.Fatal("match: Error", code, errout)
+	}
+}
+
+func Test__ROUTES_LINT(t *testing.T) {
+	if code, out, _ := runString("lint"); code != 0 || out != "" {
+		t.Fatal("lint: Error", code, out)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"lint"}, strings.NewReader(routes+"GET /users/{id} Users.Detail\nGET /posts/:name Posts.Show\n"), &stdout, &stderr)
+	if code != 1 || strings.Count(stdout.String(), "\n") != 2 {
+		t.Fatal("lint: Error", code, stdout.String())
+	}
+
+	if code, _, _ := runString("unknown"); code != 2 {
+		t.Fatal("usage: Error", code)
+	}
+}
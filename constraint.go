@@ -0,0 +1,77 @@
+package router
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// converter : 正規表現で抜き出した値を、アクションへ渡す型へ変換する関数
+type converter func(string) (interface{}, error)
+
+// constraint : パス内で :name<type> 形式で使用可能な組み込みの型制約
+type constraint struct {
+	regexp  string       // 値の形式を表す正規表現。キャプチャグループを含まないこと
+	convert converter    // 値の変換関数。nil の場合は文字列のまま渡す
+	typ     reflect.Type // 変換後の値の型
+}
+
+// constraints : 組み込みの型制約一覧
+var constraints = map[string]constraint{
+	"int": {`-?[0-9]+`, func(s string) (interface{}, error) {
+		return strconv.Atoi(s)
+	}, reflect.TypeOf(0)},
+	"uint": {`[0-9]+`, func(s string) (interface{}, error) {
+		v, err := strconv.ParseUint(s, 10, 0)
+		return uint(v), err
+	}, reflect.TypeOf(uint(0))},
+	"uuid":  {`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`, nil, stringType},
+	"slug":  {`[a-z0-9]+(?:-[a-z0-9]+)*`, nil, stringType},
+	"alpha": {`[a-zA-Z]+`, nil, stringType},
+	"hex":   {`[0-9a-fA-F]+`, nil, stringType},
+	"date": {`[0-9]{4}-[0-9]{2}-[0-9]{2}`, func(s string) (interface{}, error) {
+		return time.Parse("2006-01-02", s)
+	}, reflect.TypeOf(time.Time{})},
+}
+
+var stringType = reflect.TypeOf("")
+
+// typed : アクションの引数の型 typ の値を返却する変換関数を返却する
+// 数値の型制約は、typ のビット数を超える値を変換エラーとする。typ の値へ変換できない型制約の場合は false を返却する
+func (con constraint) typed(typ reflect.Type) (converter, bool) {
+	if con.typ.AssignableTo(typ) {
+		return con.convert, true
+	}
+	if !numericKind(con.typ.Kind()) || !numericKind(typ.Kind()) {
+		// 文字列、日付の型制約は、同じ種類の型 (ex: type ID string) へのみ変換する
+		if con.typ.Kind() != typ.Kind() || !con.typ.ConvertibleTo(typ) {
+			return nil, false
+		}
+		return func(s string) (interface{}, error) {
+			v := interface{}(s)
+			if con.convert != nil {
+				var err error
+				if v, err = con.convert(s); err != nil {
+					return nil, err
+				}
+			}
+			return reflect.ValueOf(v).Convert(typ).Interface(), nil
+		}, true
+	}
+	return func(s string) (interface{}, error) {
+		var v interface{}
+		var err error
+		switch {
+		case signedKind(typ.Kind()):
+			v, err = strconv.ParseInt(s, 10, typ.Bits())
+		case unsignedKind(typ.Kind()):
+			v, err = strconv.ParseUint(s, 10, typ.Bits())
+		default:
+			v, err = strconv.ParseFloat(s, typ.Bits())
+		}
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(v).Convert(typ).Interface(), nil
+	}, true
+}
@@ -0,0 +1,108 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type Typed struct{}
+
+func (c *Typed) Int(id int) int          { return id }
+func (c *Typed) Uint(id uint) uint       { return id }
+func (c *Typed) Str(v string) string     { return v }
+func (c *Typed) Date(d time.Time) string { return d.Format("Jan 2, 2006") }
+func (c *Typed) Int64(id int64) int64    { return id }
+func (c *Typed) Uint32(id uint32) uint32 { return id }
+func (c *Typed) Float(v float64) float64 { return v }
+func (c *Typed) Mixed(a, b string, id int) string {
+	return fmt.Sprintf("%s:%s:%d", a, b, id)
+}
+
+func Test__CONSTRAINT(t *testing.T) {
+	r := New()
+	r.AddClass(Typed{})
+	r.AddRegexp("pair", "([a-z]+)-([a-z]+)")
+	r.Register("GET", "/int/:id<int>", "Typed.Int")
+	r.Register("GET", "/uint/:id<uint>", "Typed.Uint")
+	r.Register("GET", "/uuid/:id<uuid>", "Typed.Str")
+	r.Register("GET", "/slug/:id<slug>", "Typed.Str")
+	r.Register("GET", "/alpha/:id<alpha>", "Typed.Str")
+	r.Register("GET", "/hex/:id<hex>", "Typed.Str")
+	r.Register("GET", "/date/:d<date>", "Typed.Date")
+	r.Register("GET", "/mixed/:pair/:id<int>", "Typed.Mixed")
+	r.Register("GET", "/int64/:id<int>", "Typed.Int64")
+	r.Register("GET", "/uint32/:id<uint>", "Typed.Uint32")
+	r.Register("GET", "/float/:id<int>", "Typed.Float")
+	router, err := r.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		path   string
+		result string
+	}{
+		{"/int/-12", "-12"},
+		{"/uint/12", "12"},
+		{"/uuid/123e4567-e89b-12d3-a456-426614174000", "123e4567-e89b-12d3-a456-426614174000"},
+		{"/slug/hello-world-2", "hello-world-2"},
+		{"/alpha/Hello", "Hello"},
+		{"/hex/dEadBeef", "dEadBeef"},
+		{"/date/2024-02-29", "Feb 29, 2024"},
+		{"/mixed/ab-cd/10", "ab:cd:10"},
+		// 数値はアクションの引数の型へ変換する
+		{"/int64/-9223372036854775808", "-9223372036854775808"},
+		{"/uint32/4294967295", "4294967295"},
+		{"/float/10", "10"},
+	}
+	for _, test := range tests {
+		caller, args, err := router.Caller("GET", test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := caller.Call(args)
+		if err != nil {
+			t.Fatal(test.path, err)
+		}
+		if v := fmt.Sprint(result[0].Interface()); v != test.result {
+			t.Fatal("Call: Error", test.path, v)
+		}
+	}
+
+	// 型制約を満たさない場合はマッチしない
+	for _, path := range []string{
+		"/int/1.5", "/int/99999999999999999999", "/uint/-1", "/uuid/123e4567",
+		"/slug/Hello", "/slug/a--b", "/alpha/a1", "/hex/xyz", "/date/2023-02-29", "/date/2023-2-1",
+		"/int64/9223372036854775808", "/uint32/4294967296",
+	} {
+		if _, _, err := router.Caller("GET", path); err == nil {
+			t.Fatal("Caller: Error", path)
+		}
+	}
+
+	// 未定義の型制約を指定した場合はエラーとなる
+	r = New()
+	r.AddClass(Typed{})
+	r.Register("GET", "/:id<float>", "Typed.Str")
+	if _, err := r.Create(); err == nil {
+		t.Fatal("Create: Error")
+	}
+
+	// 引数の型へ変換できない型制約は、Create でエラーとなる
+	for _, name := range []string{"Typed.Str", "Typed.Date"} {
+		r = New()
+		r.AddClass(Typed{})
+		r.Register("GET", "/:id<int>", name)
+		if _, err := r.Create(); !errors.Is(err, ErrInvalidArgument) {
+			t.Fatal("Create: Error", name, err)
+		}
+	}
+	r = New()
+	r.AddClass(Typed{})
+	r.Register("GET", "/:d<date>", "Typed.Int64")
+	if _, err := r.Create(); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatal("Create: Error", err)
+	}
+}
@@ -0,0 +1,213 @@
+package router
+
+import "errors"
+
+// errors.Is で判定可能なエラーの種類
+// ex) if errors.Is(err, router.ErrControllerNotRegistered) { ... }
+var (
+	ErrControllerNotRegistered = errors.New("controller not registered")
+	ErrAmbiguousController     = errors.New("ambiguous controller name")
+	ErrActionUndefined         = errors.New("function undefined")
+	ErrRegexpNotRegistered     = errors.New("regexp not registered")
+	ErrInvalidRegexp           = errors.New("invalid regexp")
+	ErrInvalidPath             = errors.New("invalid path")
+	ErrNotFound                = errors.New("not found")
+	ErrInvalidArgs             = errors.New("invalid arguments")
+	ErrInvalidRets             = errors.New("invalid return values")
+	ErrDependencyNotFound      = errors.New("dependency not found")
+	ErrAmbiguousDependency     = errors.New("ambiguous dependency")
+	ErrDuplicateName           = errors.New("duplicate route name")
+	ErrInvalidArgument         = errors.New("invalid argument")
+	ErrRouteConflict           = errors.New("route conflict")
+)
+
+// ControllerNotRegistered : コントローラが登録されていない場合のエラー型
+type ControllerNotRegistered struct {
+	Message    string
+	Method     string
+	Path       string
+	Controller string
+}
+
+func (err *ControllerNotRegistered) Error() string {
+	return err.Message
+}
+
+// Is : ErrControllerNotRegistered と比較した場合 true を返却する
+func (err *ControllerNotRegistered) Is(target error) bool {
+	return target == ErrControllerNotRegistered
+}
+
+func (err *ControllerNotRegistered) setRoute(method, path string) {
+	err.Method, err.Path = method, path
+}
+
+// AmbiguousController : コントローラ名に該当するコントローラが複数存在する場合のエラー型
+type AmbiguousController struct {
+	Message    string
+	Method     string
+	Path       string
+	Controller string
+	Candidates []string // 該当するコントローラのキー名
+}
+
+func (err *AmbiguousController) Error() string {
+	return err.Message
+}
+
+// Is : ErrAmbiguousController と比較した場合 true を返却する
+func (err *AmbiguousController) Is(target error) bool {
+	return target == ErrAmbiguousController
+}
+
+func (err *AmbiguousController) setRoute(method, path string) {
+	err.Method, err.Path = method, path
+}
+
+// ActionUndefined : コントローラにアクションが定義されていない場合のエラー型
+type ActionUndefined struct {
+	Message    string
+	Method     string
+	Path       string
+	Controller string
+	Action     string
+}
+
+func (err *ActionUndefined) Error() string {
+	return err.Message
+}
+
+// Is : ErrActionUndefined と比較した場合 true を返却する
+func (err *ActionUndefined) Is(target error) bool {
+	return target == ErrActionUndefined
+}
+
+func (err *ActionUndefined) setRoute(method, path string) {
+	err.Method, err.Path = method, path
+}
+
+// RegexpNotRegistered : パス内で未登録の正規表現を使用した場合のエラー型
+// ParseError.Err に格納される
+type RegexpNotRegistered struct {
+	Message string
+	Method  string
+	Path    string
+	Name    string // 正規表現のキー名
+}
+
+func (err *RegexpNotRegistered) Error() string {
+	return err.Message
+}
+
+// Is : ErrRegexpNotRegistered と比較した場合 true を返却する
+func (err *RegexpNotRegistered) Is(target error) bool {
+	return target == ErrRegexpNotRegistered
+}
+
+func (err *RegexpNotRegistered) setRoute(method, path string) {
+	err.Method, err.Path = method, path
+}
+
+// InvalidRegexp : 正規表現が不正な場合のエラー型。Err には regexp パッケージのエラーが格納される
+type InvalidRegexp struct {
+	Message string
+	Name    string // 正規表現のキー名
+	Regexp  string
+	Err     error
+}
+
+func (err *InvalidRegexp) Error() string {
+	return err.Message
+}
+
+// Unwrap : regexp パッケージのエラーを返却する
+func (err *InvalidRegexp) Unwrap() error {
+	return err.Err
+}
+
+// Is : ErrInvalidRegexp と比較した場合 true を返却する
+func (err *InvalidRegexp) Is(target error) bool {
+	return target == ErrInvalidRegexp
+}
+
+// InvalidPath : ルートパスが不正な場合のエラー型
+// パスを正規表現として解釈できない場合、Err には regexp パッケージのエラーが格納される
+type InvalidPath struct {
+	Message    string
+	Method     string
+	Path       string
+	Controller string
+	Err        error
+}
+
+func (err *InvalidPath) Error() string {
+	return err.Message
+}
+
+// Unwrap : 原因となったエラーを返却する
+func (err *InvalidPath) Unwrap() error {
+	return err.Err
+}
+
+// Is : ErrInvalidPath と比較した場合 true を返却する
+func (err *InvalidPath) Is(target error) bool {
+	return target == ErrInvalidPath
+}
+
+func (err *InvalidPath) setRoute(method, path string) {
+	err.Method, err.Path = method, path
+}
+
+// DuplicateName : ルート名が他のルートパスで使用されている場合のエラー型
+type DuplicateName struct {
+	Message string
+	Name    string // ルート名
+	Method  string // ルート名を使用しているルートパスのメソッド
+	Path    string // ルート名を使用しているルートパス
+}
+
+func (err *DuplicateName) Error() string {
+	return err.Message
+}
+
+// Is : ErrDuplicateName と比較した場合 true を返却する
+func (err *DuplicateName) Is(target error) bool {
+	return target == ErrDuplicateName
+}
+
+// InvalidArgument : Register、Create などへ与えた値が不正な場合のエラー型
+// 値の変換に失敗した場合、Err には原因となったエラーが格納される
+type InvalidArgument struct {
+	Message string
+	Method  string
+	Path    string
+	Err     error
+}
+
+func (err *InvalidArgument) Error() string {
+	return err.Message
+}
+
+// Unwrap : 原因となったエラーを返却する
+func (err *InvalidArgument) Unwrap() error {
+	return err.Err
+}
+
+// Is : ErrInvalidArgument と比較した場合 true を返却する
+func (err *InvalidArgument) Is(target error) bool {
+	return target == ErrInvalidArgument
+}
+
+func (err *InvalidArgument) setRoute(method, path string) {
+	err.Method, err.Path = method, path
+}
+
+// withRoute : エラーと、エラーが内包するエラーへ、発生したルートのメソッド、パスを設定する
+func withRoute(err error, method, path string) error {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if v, ok := e.(interface{ setRoute(string, string) }); ok {
+			v.setRoute(method, path)
+		}
+	}
+	return err
+}
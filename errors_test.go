@@ -0,0 +1,185 @@
+package router
+
+import (
+	"errors"
+	"reflect"
+	"regexp/syntax"
+	"testing"
+
+	"github.com/ochipin/router/internal/sample"
+)
+
+func Test__ERRORS_CREATE(t *testing.T) {
+	// 未登録のコントローラ
+	r := New()
+	r.Register("GET", "/users", "Users.Index")
+	_, err := r.Create()
+	var notRegistered *ControllerNotRegistered
+	if !errors.Is(err, ErrControllerNotRegistered) || !errors.As(err, &notRegistered) {
+		t.Fatal("Create: Error", err)
+	}
+	if notRegistered.Method != "GET" || notRegistered.Path != "/users" || notRegistered.Controller != "Users" {
+		t.Fatal("ControllerNotRegistered: Error", notRegistered)
+	}
+
+	// 同名のコントローラが複数存在する
+	r = New()
+	r.AddClass(Sample{})
+	r.AddClass(sample.Sample{})
+	r.Register("GET", "/", "Sample.Index")
+	_, err = r.Create()
+	var ambiguous *AmbiguousController
+	if !errors.Is(err, ErrAmbiguousController) || !errors.As(err, &ambiguous) || len(ambiguous.Candidates) != 2 {
+		t.Fatal("Create: Error", err)
+	}
+
+	// 未定義のアクション
+	r = New()
+	r.AddClass(Sample{})
+	r.Register("POST", "/", "Sample.Undefined")
+	_, err = r.Create()
+	var undefined *ActionUndefined
+	if !errors.Is(err, ErrActionUndefined) || !errors.As(err, &undefined) {
+		t.Fatal("Create: Error", err)
+	}
+	if undefined.Method != "POST" || undefined.Path != "/" || undefined.Action != "Undefined" {
+		t.Fatal("ActionUndefined: Error", undefined)
+	}
+
+	// 未登録の正規表現は、ParseError から取得できる
+	r = New()
+	r.AddClass(Sample{})
+	r.Register("GET", "/users/:id", "Sample.TheTest")
+	_, err = r.Create()
+	var parseErr *ParseError
+	var notFound *RegexpNotRegistered
+	if !errors.As(err, &parseErr) || !errors.Is(err, ErrInvalidPath) || !errors.Is(err, ErrRegexpNotRegistered) {
+		t.Fatal("Create: Error", err)
+	}
+	if !errors.As(err, &notFound) || notFound.Name != "id" || notFound.Method != "GET" || notFound.Path != "/users/:id" {
+		t.Fatal("RegexpNotRegistered: Error", notFound)
+	}
+	if parseErr.Method != "GET" || parseErr.Path != "/users/:id" {
+		t.Fatal("ParseError: Error", parseErr)
+	}
+}
+
+func Test__ERRORS_REGEXP(t *testing.T) {
+	// 不正な正規表現は、regexp パッケージのエラーを内包する
+	r := New()
+	err := r.AddRegexp("id", "([0-9]+")
+	var invalid *InvalidRegexp
+	var syntaxErr *syntax.Error
+	if !errors.Is(err, ErrInvalidRegexp) || !errors.As(err, &invalid) || !errors.As(err, &syntaxErr) {
+		t.Fatal("AddRegexp: Error", err)
+	}
+	if invalid.Name != "id" || invalid.Regexp != "([0-9]+" {
+		t.Fatal("InvalidRegexp: Error", invalid)
+	}
+
+	// 空のパスを登録した場合
+	if err := r.Register("GET", "", "Sample.Index"); !errors.Is(err, ErrInvalidPath) {
+		t.Fatal("Register: Error", err)
+	}
+
+	// 構文エラーは、登録したルートパスのメソッド、パスを持つ
+	var parseErr *ParseError
+	if err := r.Register("GET", "/users/:id/[0-9]+", "Sample.Index"); !errors.As(err, &parseErr) || parseErr.Method != "GET" || parseErr.Path != "/users/:id/[0-9]+" {
+		t.Fatal("Register: Error", err)
+	}
+}
+
+func Test__ERRORS_REGISTER(t *testing.T) {
+	var invalid *InvalidArgument
+	r := New()
+	// 不正なアクション名、制限時間、リダイレクトのステータスコード
+	for _, err := range []error{
+		r.Register("GET", "/users", "Users"),
+		r.Register("GET", "/users", ""),
+		r.RegisterTimeout("GET", "/users", "Users.Index", -1),
+		r.RegisterRedirect("GET", "/users", "/people", 200),
+	} {
+		if !errors.Is(err, ErrInvalidArgument) || !errors.As(err, &invalid) || invalid.Method != "GET" || invalid.Path != "/users" {
+			t.Fatal("Register: Error", err)
+		}
+	}
+
+	// Generator が nil
+	r.Generator = nil
+	if _, err := r.Create(); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatal("Create: Error", err)
+	}
+
+	// 既定値を型制約に従い変換できない場合は、変換のエラーを内包する
+	r = New()
+	r.AddClass(Posts{})
+	r.RegisterDefaults("GET", "/archive(/:year<int>)", "Posts.Archive", map[string]interface{}{"year": "abc"})
+	_, err := r.Create()
+	if !errors.Is(err, ErrInvalidArgument) || !errors.As(err, &invalid) || invalid.Err == nil || invalid.Method != "GET" {
+		t.Fatal("Create: Error", err)
+	}
+
+	// 正規化後のキーが重複する固定パス
+	r = New()
+	r.Options.CaseInsensitive = true
+	r.AddClass(Sample{})
+	r.Register("GET", "/hello", "Sample.Hello")
+	r.Register("GET", "/HELLO", "Sample.Hello")
+	var conflict *RouteConflict
+	if _, err := r.Create(); !errors.Is(err, ErrRouteConflict) || !errors.As(err, &conflict) || conflict.Method != "GET" {
+		t.Fatal("Create: Error", err)
+	}
+}
+
+func Test__ERRORS_CALLER(t *testing.T) {
+	r := New()
+	r.AddRegexp("id", "([0-9]+)")
+	r.AddClass(Sample{})
+	r.Register("GET", "/users/:id", "Sample.TheTest")
+	r.Register("GET", "/hello", "Sample.Hello")
+	router, err := r.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 該当するアクションが存在しない
+	_, _, err = router.Caller("GET", "/undefined")
+	if !errors.Is(err, ErrNotFound) || errors.Unwrap(err) != nil {
+		t.Fatal("Caller: Error", err)
+	}
+	// 不正なエスケープの場合は、原因となったエラーを内包する
+	_, _, err = router.Caller("GET", "/users/%zz")
+	if !errors.Is(err, ErrNotFound) || errors.Unwrap(err) == nil {
+		t.Fatal("Caller: Error", err)
+	}
+
+	// 引数、復帰値の不一致
+	action, args, err := router.Caller("GET", "/users/10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := action.Call(args, "string"); !errors.Is(err, ErrInvalidRets) {
+		t.Fatal("Call: Error", err)
+	}
+	if _, err := action.Call(nil); !errors.Is(err, ErrInvalidArgs) {
+		t.Fatal("Call: Error", err)
+	}
+	if _, err := action.Call([]reflect.Value{reflect.ValueOf(10)}); !errors.Is(err, ErrInvalidArgs) {
+		t.Fatal("Call: Error", err)
+	}
+}
+
+func Test__ERRORS_INJECT(t *testing.T) {
+	r := New()
+	r.Container = NewContainer()
+	r.AddClass(Inject{})
+	r.Register("GET", "/", "Inject.Show")
+	_, err := r.Create()
+	var dep *DependencyNotFound
+	if !errors.Is(err, ErrDependencyNotFound) || !errors.As(err, &dep) {
+		t.Fatal("Create: Error", err)
+	}
+	if dep.Method != "GET" || dep.Path != "/" || dep.Ctlname != "Inject" {
+		t.Fatal("DependencyNotFound: Error", dep)
+	}
+}
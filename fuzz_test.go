@@ -0,0 +1,148 @@
+package router
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// FuzzParsePath : パスの解析、正規表現への変換が panic せず、整合性のある結果を返却することを検証する
+func FuzzParsePath(f *testing.F) {
+	for _, seed := range []string{
+		"/", "/users/:id", "/users/:id<int>", "/posts/{year:[0-9]{4}}/{slug}", "/archive(/:year<int>(/:month<int>))",
+		"/users/{", "/users/:id<", "/(", "/)", "/{id:(x)}", "/ユーザー/{id", "/{id:\\}}",
+	} {
+		f.Add(seed)
+	}
+	r := New()
+	r.AddRegexp("id", "([0-9]+)")
+	f.Fuzz(func(t *testing.T, path string) {
+		tokens, err := parsePath(path)
+		if err != nil {
+			v, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("%q - unexpected error type %T", path, err)
+			}
+			if v.Path != path || v.Column < 1 || v.Column > utf8.RuneCountInString(path)+1 {
+				t.Fatalf("%q - invalid column %d", path, v.Column)
+			}
+			return
+		}
+		// 固定文字列を連結した結果は、元のパスの固定文字列部分と一致する
+		var literal strings.Builder
+		depth := 0
+		for _, tok := range tokens {
+			switch tok.kind {
+			case tokenLiteral:
+				literal.WriteString(tok.text)
+			case tokenOpen:
+				depth++
+			case tokenClose:
+				depth--
+			}
+			if depth < 0 {
+				t.Fatalf("%q - unbalanced tokens", path)
+			}
+		}
+		if depth != 0 {
+			t.Fatalf("%q - unbalanced tokens", path)
+		}
+		if !strings.ContainsAny(path, reserved) && literal.String() != path {
+			t.Fatalf("%q - literal mismatch %q", path, literal.String())
+		}
+
+		// 正規表現へ変換できた場合は、コンパイル可能であり、キャプチャグループ数が一致する
+		c, err := r.compile(path)
+		if err != nil {
+			if _, ok := err.(*ParseError); !ok {
+				t.Fatalf("%q - unexpected error type %T", path, err)
+			}
+			return
+		}
+		re, err := regexp.Compile("^" + c.pattern + "$")
+		if err != nil {
+			t.Fatalf("%q - %s", path, err)
+		}
+		if re.NumSubexp() != len(c.converters) || len(c.names) != len(c.converters) || len(c.optional) != len(c.converters) {
+			t.Fatalf("%q - group mismatch %d, %d", path, re.NumSubexp(), len(c.converters))
+		}
+	})
+}
+
+// FuzzRoute : Register、Create、Caller が panic せず、固定パスは登録したパスで取得できることを検証する
+func FuzzRoute(f *testing.F) {
+	for _, seed := range [][2]string{
+		{"/users", "/users"},
+		{"/users/:id", "/users/10"},
+		{"/users/{name}", "/users/a%2Fb"},
+		{"/archive(/:year<int>)", "/archive/2024"},
+		{"/a b", "/a%20b"},
+		{"/%zz", "/%zz"},
+	} {
+		f.Add(seed[0], seed[1])
+	}
+	f.Fuzz(func(t *testing.T, path, request string) {
+		r := New()
+		r.AddRegexp("id", "([0-9]+)")
+		if err := r.Register("GET", path, "Sample.Index"); err != nil {
+			return
+		}
+		router, err := r.CreateDry()
+		if err != nil {
+			return
+		}
+		if _, _, err := router.Caller("GET", request); err != nil {
+			if _, ok := err.(*NotRoutes); !ok {
+				t.Fatalf("%q, %q - unexpected error type %T", path, request, err)
+			}
+		}
+		// '%' を含む固定パスは、エスケープとして解釈されるため対象外とする
+		if strings.ContainsAny(path, reserved) || strings.Contains(path, "%") || !utf8.ValidString(path) {
+			return
+		}
+		escaped := (&url.URL{Path: path}).EscapedPath()
+		action, args, err := router.Caller("GET", escaped)
+		if err != nil {
+			t.Fatalf("%q - %s", path, err)
+		}
+		if ctlname, actname := action.Name(); ctlname != "Sample" || actname != "Index" || len(args) != 0 {
+			t.Fatalf("%q - unexpected action %s.%s", path, ctlname, actname)
+		}
+	})
+}
+
+// FuzzBuildPath : BuildPath で生成したパスを Caller で照合すると、埋め込んだ値が得られることを検証する
+func FuzzBuildPath(f *testing.F) {
+	f.Add("hello", 10, "tag", true)
+	f.Add("a/b c%d", -1, "", false)
+	f.Add("日本語", 0, "x?y#z", true)
+	r := New()
+	r.AddRegexp("tag", "([^/]+)")
+	r.Register("GET", "/posts/{slug}/:id<int>(/tag/:tag)", "Posts.Show")
+	router, err := r.CreateDry()
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Fuzz(func(t *testing.T, slug string, id int, tag string, withTag bool) {
+		params := map[string]interface{}{"slug": slug, "id": id}
+		if withTag {
+			params["tag"] = tag
+		}
+		path, err := r.BuildPath("/posts/{slug}/:id<int>(/tag/:tag)", params)
+		if err != nil {
+			return
+		}
+		_, args, err := router.Caller("GET", path)
+		if err != nil {
+			t.Fatalf("%q - %s", path, err)
+		}
+		if len(args) != 3 || args[0].Interface() != slug || args[1].Interface() != id {
+			t.Fatalf("%q - unexpected args %v", path, args)
+		}
+		if withTag && args[2].Interface() != tag {
+			t.Fatalf("%q - unexpected tag %v", path, args[2])
+		}
+	})
+}
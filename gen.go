@@ -0,0 +1,292 @@
+package router
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Generate : 登録されているルートパスから、リフレクションを使用せずにアクションを呼び出す Generator のソースコードを出力する
+// pkg には出力先のパッケージのインポートパスを指定する。パッケージ名はインポートパスの末尾の要素とする。
+// 出力した RouteGenerator を RouteTable.Generator へ設定することで、アクションを直接呼び出す。
+// コントローラ、アクションが存在しない場合はコンパイルエラーとなる
+//
+//	//go:generate go run ./gen
+//
+// Factory、Container、Timeout を使用するアクションも直接呼び出す。引数、復帰値の型が一致しない場合は、従来どおりリフレクションで呼び出す
+func (rt *RouteTable) Generate(w io.Writer, pkg string) error {
+	g := &generator{pkg: pkg, imports: make(map[string]string)}
+
+	// 同じアクションを指す名前をまとめ、ルートID を割り当てる
+	var (
+		targets = make(map[string]*genRoute)
+		keys    []string
+	)
+	for _, routes := range rt.routes {
+		for _, route := range routes {
+			// リダイレクト、別名のルートパスは、アクションを持たない
+			if route.redirect != "" || route.alias != "" {
+				continue
+			}
+			key, controller, err := rt.lookupClass(route.ctlname)
+			if err != nil {
+				return err
+			}
+			id := key + "." + route.actname
+			target, ok := targets[id]
+			if !ok {
+				target = &genRoute{typ: structType(reflect.TypeOf(controller)), actname: route.actname}
+				targets[id] = target
+				keys = append(keys, id)
+			}
+			name := route.ctlname + "." + route.actname
+			if !containsString(target.names, name) {
+				target.names = append(target.names, name)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	var body bytes.Buffer
+	var cases []string
+	for i, key := range keys {
+		target := targets[key]
+		sort.Strings(target.names)
+		ok, err := g.route(&body, i, target)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		cases = append(cases, fmt.Sprintf("case %s:\n\treturn route%d{action}\n", quoteAll(target.names), i))
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by github.com/ochipin/router. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %s\n\n", path.Base(pkg))
+	fmt.Fprintf(&src, "import (\n\"context\"\n\"reflect\"\n\n\"github.com/ochipin/router\"\n")
+	var paths []string
+	for p := range g.imports {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		fmt.Fprintf(&src, "%s %q\n", g.imports[p], p)
+	}
+	fmt.Fprintf(&src, ")\n\n")
+	fmt.Fprintf(&src, "// RouteGenerator : 登録されているアクションを、リフレクションを使用せずに呼び出す Generator\n")
+	fmt.Fprintf(&src, "type RouteGenerator struct{}\n\n")
+	fmt.Fprintf(&src, "// Action : ルートID に対応するアクションを生成する\n")
+	fmt.Fprintf(&src, "func (RouteGenerator) Action(ctlname, actname string, controller interface{}) router.Result {\n")
+	fmt.Fprintf(&src, "action := &router.Action{Ctlname: ctlname, Actname: actname, Controller: controller}\n")
+	if len(cases) != 0 {
+		fmt.Fprintf(&src, "switch ctlname + \".\" + actname {\n%s}\n", strings.Join(cases, ""))
+	}
+	fmt.Fprintf(&src, "return action\n}\n\n")
+	fmt.Fprintf(&src, "// routeRets : 復帰値の型の指定が無いか、want と一致するか判定する\n")
+	fmt.Fprintf(&src, "func routeRets(ret []string, want ...string) bool {\n")
+	fmt.Fprintf(&src, "if len(ret) == 0 {\nreturn true\n}\n")
+	fmt.Fprintf(&src, "if len(ret) != len(want) {\nreturn false\n}\n")
+	fmt.Fprintf(&src, "for i := range ret {\nif ret[i] != want[i] {\nreturn false\n}\n}\nreturn true\n}\n")
+	src.Write(body.Bytes())
+
+	out, err := format.Source(src.Bytes())
+	if err != nil {
+		return fmt.Errorf("'%s' - %s", pkg, err)
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// genRoute : 静的に呼び出すアクション
+type genRoute struct {
+	typ     reflect.Type // コントローラの構造体の型
+	actname string
+	names   []string // アクションを指す Controller.Action 形式の名前
+}
+
+// generator : ソースコードの出力に必要な情報
+type generator struct {
+	pkg     string            // 出力先のパッケージのインポートパス
+	imports map[string]string // インポートパスと別名
+}
+
+// route : アクションを直接呼び出す Result 型を出力する。静的に呼び出せないアクションの場合は false を返却する
+func (g *generator) route(w io.Writer, id int, target *genRoute) (bool, error) {
+	method, ok := reflect.PtrTo(target.typ).MethodByName(target.actname)
+	if !ok {
+		return false, &ActionUndefined{
+			Message:    fmt.Sprintf("'%s.%s' - function undefined", target.typ.Name(), target.actname),
+			Controller: target.typ.Name(),
+			Action:     target.actname,
+		}
+	}
+	ctl, ok := g.typeExpr(target.typ)
+	if !ok || !method.IsExported() || method.Type.IsVariadic() {
+		return false, nil
+	}
+
+	// 第1引数 (レシーバ) を除いた引数、復帰値の型を求める
+	fn := method.Type
+	offset := 1
+	withContext := fn.NumIn() > 1 && fn.In(1) == contextType
+	if withContext {
+		offset = 2
+	}
+	var params, rets, retnames []string
+	for i := offset; i < fn.NumIn(); i++ {
+		expr, ok := g.typeExpr(fn.In(i))
+		if !ok {
+			return false, nil
+		}
+		params = append(params, expr)
+	}
+	for i := 0; i < fn.NumOut(); i++ {
+		if _, ok := g.typeExpr(fn.Out(i)); !ok {
+			return false, nil
+		}
+		rets = append(rets, fmt.Sprintf("o%d", i))
+		retnames = append(retnames, fmt.Sprintf("%q", fn.Out(i).String()))
+	}
+
+	name := fmt.Sprintf("route%d", id)
+	fmt.Fprintf(w, "\n// %s : %s\n", name, strings.Join(target.names, ", "))
+	fmt.Fprintf(w, "type %s struct{ *router.Action }\n\n", name)
+	fmt.Fprintf(w, "func (r %s) Call(args []reflect.Value, ret ...string) ([]reflect.Value, error) {\n", name)
+	fmt.Fprintf(w, "return r.CallContext(context.Background(), args, ret...)\n}\n\n")
+	fmt.Fprintf(w, "func (r %s) CallContext(ctx context.Context, args []reflect.Value, ret ...string) ([]reflect.Value, error) {\n", name)
+	fmt.Fprintf(w, "if len(args) != %d || !routeRets(ret%s) {\n", len(params), prefixAll(", ", retnames))
+	fmt.Fprintf(w, "return r.Action.CallContext(ctx, args, ret...)\n}\n")
+	fmt.Fprintf(w, "if ctx == nil {\nctx = context.Background()\n}\n")
+	fmt.Fprintf(w, "if err := ctx.Err(); err != nil {\nreturn nil, err\n}\n")
+	var args []string
+	if withContext {
+		args = append(args, "ctx")
+	}
+	for i, param := range params {
+		fmt.Fprintf(w, "a%d, ok := args[%d].Interface().(%s)\n", i, i, param)
+		fmt.Fprintf(w, "if !ok {\nreturn r.Action.CallContext(ctx, args, ret...)\n}\n")
+		args = append(args, fmt.Sprintf("a%d", i))
+	}
+	// 生成関数、サービスの注入、実行制限時間も、リフレクションを使用せずに処理する
+	fmt.Fprintf(w, "v, err := r.Instance(new(%s))\n", ctl)
+	fmt.Fprintf(w, "if err != nil {\nreturn nil, err\n}\n")
+	fmt.Fprintf(w, "c, ok := v.(*%s)\n", ctl)
+	fmt.Fprintf(w, "if !ok {\nreturn r.Action.CallContext(ctx, args, ret...)\n}\n")
+	fmt.Fprintf(w, "if r.Timeout > 0 {\nvar cancel context.CancelFunc\nctx, cancel = context.WithTimeout(ctx, r.Timeout)\ndefer cancel()\n}\n")
+	call := fmt.Sprintf("c.%s(%s)", target.actname, strings.Join(args, ", "))
+	fmt.Fprintf(w, "call := func() []reflect.Value {\n")
+	if len(rets) == 0 {
+		fmt.Fprintf(w, "%s\nreturn []reflect.Value{}\n}\n", call)
+	} else {
+		fmt.Fprintf(w, "%s := %s\n", strings.Join(rets, ", "), call)
+		for i := range rets {
+			rets[i] = fmt.Sprintf("reflect.ValueOf(&o%d).Elem()", i)
+		}
+		fmt.Fprintf(w, "return []reflect.Value{%s}\n}\n", strings.Join(rets, ", "))
+	}
+	fmt.Fprintf(w, "if r.Timeout <= 0 {\nreturn call(), nil\n}\n")
+	fmt.Fprintf(w, "return r.CallTimeout(ctx, call)\n}\n")
+	return true, nil
+}
+
+// typeExpr : 型をソースコード上の表記へ変換する。表記できない型の場合は false を返却する
+func (g *generator) typeExpr(typ reflect.Type) (string, bool) {
+	if typ.Name() != "" {
+		if typ.PkgPath() == "" || typ.PkgPath() == g.pkg {
+			return typ.Name(), true
+		}
+		// 他パッケージの非公開の型、型パラメータを伴う型は参照できない
+		if !isExported(typ.Name()) || strings.ContainsAny(typ.Name(), "[]") {
+			return "", false
+		}
+		return g.qualifier(typ.PkgPath()) + "." + typ.Name(), true
+	}
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice:
+		elem, ok := g.typeExpr(typ.Elem())
+		prefix := "*"
+		if typ.Kind() == reflect.Slice {
+			prefix = "[]"
+		}
+		return prefix + elem, ok
+	case reflect.Array:
+		elem, ok := g.typeExpr(typ.Elem())
+		return fmt.Sprintf("[%d]%s", typ.Len(), elem), ok
+	case reflect.Map:
+		key, ok := g.typeExpr(typ.Key())
+		elem, ok2 := g.typeExpr(typ.Elem())
+		return "map[" + key + "]" + elem, ok && ok2
+	case reflect.Interface:
+		if typ.NumMethod() == 0 {
+			return "interface{}", true
+		}
+	}
+	return "", false
+}
+
+// qualifier : インポートパスに対応するパッケージの別名を返却する
+func (g *generator) qualifier(pkgpath string) string {
+	if name, ok := g.imports[pkgpath]; ok {
+		return name
+	}
+	base := strings.Map(func(r rune) rune {
+		if r == '_' || ('0' <= r && r <= '9') || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') {
+			return r
+		}
+		return '_'
+	}, path.Base(pkgpath))
+	// 生成するソースコードで使用する名前、他のパッケージの別名と重複しないようにする
+	used := map[string]bool{"context": true, "reflect": true, "router": true}
+	for _, name := range []string{"action", "ctlname", "actname", "controller", "ctx", "args", "ret", "ok", "c", "r", "v", "err", "call", "cancel"} {
+		used[name] = true
+	}
+	for _, name := range g.imports {
+		used[name] = true
+	}
+	name := base
+	for i := 2; used[name]; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	g.imports[pkgpath] = name
+	return name
+}
+
+// isExported : 公開された名前か判定する
+func isExported(name string) bool {
+	return name != "" && 'A' <= name[0] && name[0] <= 'Z'
+}
+
+// containsString : 文字列が含まれるか判定する
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// quoteAll : 文字列をクォートし、カンマ区切りで連結する
+func quoteAll(list []string) string {
+	quoted := make([]string, len(list))
+	for i, v := range list {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// prefixAll : 各文字列の先頭に prefix を付与し、連結する
+func prefixAll(prefix string, list []string) string {
+	var b strings.Builder
+	for _, v := range list {
+		b.WriteString(prefix + v)
+	}
+	return b.String()
+}
@@ -0,0 +1,58 @@
+package router
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type Generated struct{}
+
+func (c *Generated) Index(ctx, v string) string        { return v }
+func (c *Generated) Each(fn func(string)) string       { return "" }
+func (c *Generated) Join(v ...string) string           { return strings.Join(v, ",") }
+func (c *Generated) Local(v Value) (TestString, error) { return "", nil }
+
+func Test__GENERATE(t *testing.T) {
+	r := New()
+	r.AddClass(Generated{})
+	r.Register("GET", "/", "Generated.Index")
+	r.Register("GET", "/each", "Generated.Each")
+	r.Register("GET", "/join", "Generated.Join")
+	r.Register("GET", "/local", "Generated.Local")
+	// リダイレクト、別名のルートパスは出力しない
+	r.RegisterRedirect("GET", "/old", "/", http.StatusMovedPermanently)
+	r.RegisterAlias("GET", "/home", "/")
+	var b bytes.Buffer
+	if err := r.Generate(&b, "example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	src := b.String()
+	// 関数型、可変長引数を伴うアクションは、静的に呼び出さない
+	for _, want := range []string{
+		"package app\n", `router2 "github.com/ochipin/router"`, `case "Generated.Index":`, `case "Generated.Local":`,
+		"r.Instance(new(router2.Generated))", "r.CallTimeout(ctx, call)", "a0, ok := args[0].Interface().(router2.Value)", `routeRets(ret, "router.TestString", "error")`,
+	} {
+		if !strings.Contains(src, want) {
+			t.Fatal("Generate: Error", want, src)
+		}
+	}
+	// 生成関数、サービス、実行制限時間の有無では、リフレクションへ切り替えない
+	for _, unwanted := range []string{"Generated.Each", "Generated.Join", "r.Factory != nil", "r.Timeout > 0 ||"} {
+		if strings.Contains(src, unwanted) {
+			t.Fatal("Generate: Error", unwanted, src)
+		}
+	}
+
+	// コントローラ、アクションが存在しない場合はエラーとなる
+	r.Register("GET", "/none", "Generated.None")
+	if err := r.Generate(&b, "example.com/app"); err == nil {
+		t.Fatal("Generate: Error")
+	}
+	r = New()
+	r.Register("GET", "/", "Unknown.Index")
+	if err := r.Generate(&b, "example.com/app"); err == nil {
+		t.Fatal("Generate: Error")
+	}
+}
@@ -1 +1,3 @@
 module github.com/ochipin/router
+
+go 1.18
@@ -0,0 +1,308 @@
+package router
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Container : コントローラへ注入するサービスを管理する構造体
+// コントローラのフィールドに `inject:"name"` タグを付与した場合は名前で、
+// `inject:""` タグを付与した場合はフィールドの型でサービスを検索し、インスタンス生成時に注入する。
+// `inject:"name,optional"` の場合、サービスが登録されていなくてもエラーとはならない
+type Container struct {
+	named map[string]*provider       // 名前で登録されたサービス
+	typed map[reflect.Type]*provider // 型で登録されたサービス
+}
+
+// provider : サービスの提供方法を管理する構造体
+type provider struct {
+	typ   reflect.Type  // 提供するサービスの型
+	value reflect.Value // シングルトンの場合の値
+	fn    reflect.Value // リクエスト単位の場合の生成関数
+}
+
+// errorType : error の型情報
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// NewContainer : サービス管理用構造体を生成する
+func NewContainer() *Container {
+	return &Container{
+		named: make(map[string]*provider),
+		typed: make(map[reflect.Type]*provider),
+	}
+}
+
+// Singleton : 全インスタンスで共有するサービスを登録する
+// name が空文字列の場合は、i の型で登録する
+func (c *Container) Singleton(name string, i interface{}) error {
+	v := reflect.ValueOf(i)
+	if v.IsValid() == false {
+		return fmt.Errorf("'%s' - invalid argument. is nil", name)
+	}
+	return c.add(name, &provider{typ: v.Type(), value: v})
+}
+
+// Provide : インスタンス生成のたびに fn を実行して生成するサービスを登録する
+// fn は func() T、または func() (T, error) 形式の関数であること。name が空文字列の場合は、T の型で登録する
+func (c *Container) Provide(name string, fn interface{}) error {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return fmt.Errorf("'%s' - invalid argument. provider is not function", name)
+	}
+	typ := v.Type()
+	if typ.NumIn() != 0 || typ.NumOut() == 0 || typ.NumOut() > 2 {
+		return fmt.Errorf("'%s' - invalid provider. '%s' is not func() T or func() (T, error)", name, typ)
+	}
+	if typ.NumOut() == 2 && typ.Out(1) != errorType {
+		return fmt.Errorf("'%s' - invalid provider. '%s' is not func() T or func() (T, error)", name, typ)
+	}
+	return c.add(name, &provider{typ: typ.Out(0), fn: v})
+}
+
+func (c *Container) add(name string, p *provider) error {
+	if c.named == nil {
+		c.named = make(map[string]*provider)
+	}
+	if c.typed == nil {
+		c.typed = make(map[reflect.Type]*provider)
+	}
+	if name == "" {
+		c.typed[p.typ] = p
+	} else {
+		c.named[name] = p
+	}
+	return nil
+}
+
+// get : 名前、または型に該当するサービスの提供者を返却する
+// インタフェース型の場合、実装しているサービスが複数登録されていれば、その型名を candidates へ格納して nil を返却する
+func (c *Container) get(name string, typ reflect.Type) (p *provider, candidates []string) {
+	if name != "" {
+		return c.named[name], nil
+	}
+	if p, ok := c.typed[typ]; ok {
+		return p, nil
+	}
+	// インタフェース型の場合は、実装しているサービスを検索する
+	if typ.Kind() == reflect.Interface {
+		for t, v := range c.typed {
+			if t.Implements(typ) {
+				p = v
+				candidates = append(candidates, t.String())
+			}
+		}
+	}
+	if len(candidates) > 1 {
+		sort.Strings(candidates)
+		return nil, candidates
+	}
+	return p, nil
+}
+
+// lookup : フィールドへ注入するサービスの提供者を返却する
+// 該当するサービスが複数存在する場合は AmbiguousDependency を返却する
+func (c *Container) lookup(ctlname string, field reflect.StructField, name string) (*provider, error) {
+	p, candidates := c.get(name, field.Type)
+	if len(candidates) > 1 {
+		return nil, &AmbiguousDependency{
+			Message: fmt.Sprintf("'%s.%s' - ambiguous dependency '%s'. candidates: %s",
+				ctlname, field.Name, field.Type, strings.Join(candidates, ", ")),
+			Ctlname:    ctlname,
+			Field:      field.Name,
+			Candidates: candidates,
+		}
+	}
+	return p, nil
+}
+
+// Check : 指定した型のコントローラが要求するサービスが、すべて登録されているか確認する
+func (c *Container) Check(ctlname string, typ reflect.Type) error {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+	return c.walk(ctlname, typ, func(field reflect.StructField, name string, optional bool) error {
+		p, err := c.lookup(ctlname, field, name)
+		if err != nil {
+			return err
+		}
+		if p == nil {
+			if optional {
+				return nil
+			}
+			return c.notFound(ctlname, field, name)
+		}
+		if !p.typ.AssignableTo(field.Type) {
+			return &DependencyNotFound{
+				Message: fmt.Sprintf("'%s.%s' - cannot use (type %s) as type %s in injection",
+					ctlname, field.Name, p.typ, field.Type),
+				Ctlname: ctlname,
+				Field:   field.Name,
+				Name:    name,
+			}
+		}
+		return nil
+	})
+}
+
+// Inject : elem が指す構造体のタグ付きフィールドへ、サービスを注入する
+func (c *Container) Inject(ctlname string, elem reflect.Value) error {
+	for elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return nil
+	}
+	return c.inject(ctlname, elem)
+}
+
+func (c *Container) inject(ctlname string, elem reflect.Value) error {
+	typ := elem.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		// 埋め込まれた構造体のフィールドも注入の対象とする
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if err := c.inject(ctlname, elem.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+		tag, ok := field.Tag.Lookup("inject")
+		if !ok {
+			continue
+		}
+		name, optional := parseInjectTag(tag)
+		p, err := c.lookup(ctlname, field, name)
+		if err != nil {
+			return err
+		}
+		if p == nil {
+			if optional {
+				continue
+			}
+			return c.notFound(ctlname, field, name)
+		}
+		v, err := p.provide()
+		if err != nil {
+			return fmt.Errorf("'%s.%s' - %w", ctlname, field.Name, err)
+		}
+		if !elem.Field(i).CanSet() {
+			return &InvalidArgument{Message: fmt.Sprintf("'%s.%s' - cannot inject into unexported field", ctlname, field.Name)}
+		}
+		elem.Field(i).Set(v)
+	}
+	return nil
+}
+
+// walk : 注入対象となるフィールドを順に処理する
+func (c *Container) walk(ctlname string, typ reflect.Type, fn func(reflect.StructField, string, bool) error) error {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if err := c.walk(ctlname, field.Type, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		tag, ok := field.Tag.Lookup("inject")
+		if !ok {
+			continue
+		}
+		if field.PkgPath != "" {
+			return &InvalidArgument{Message: fmt.Sprintf("'%s.%s' - cannot inject into unexported field", ctlname, field.Name)}
+		}
+		name, optional := parseInjectTag(tag)
+		if err := fn(field, name, optional); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Container) notFound(ctlname string, field reflect.StructField, name string) error {
+	var dep = name
+	if dep == "" {
+		dep = field.Type.String()
+	}
+	return &DependencyNotFound{
+		Message: fmt.Sprintf("'%s.%s' - dependency '%s' not registered", ctlname, field.Name, dep),
+		Ctlname: ctlname,
+		Field:   field.Name,
+		Name:    dep,
+	}
+}
+
+// provide : サービスの値を返却する
+func (p *provider) provide() (reflect.Value, error) {
+	if p.fn.IsValid() == false {
+		return p.value, nil
+	}
+	out := p.fn.Call(nil)
+	if len(out) == 2 && !out[1].IsNil() {
+		return reflect.Value{}, out[1].Interface().(error)
+	}
+	return out[0], nil
+}
+
+// parseInjectTag : `inject:"name,optional"` 形式のタグを解析する
+func parseInjectTag(tag string) (string, bool) {
+	var optional bool
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		if strings.TrimSpace(opt) == "optional" {
+			optional = true
+		}
+	}
+	return strings.TrimSpace(parts[0]), optional
+}
+
+// DependencyNotFound : コントローラが要求するサービスが登録されていない場合のエラー型
+type DependencyNotFound struct {
+	Message string
+	Method  string
+	Path    string
+	Ctlname string
+	Field   string
+	Name    string
+}
+
+func (err *DependencyNotFound) Error() string {
+	return err.Message
+}
+
+// Is : ErrDependencyNotFound と比較した場合 true を返却する
+func (err *DependencyNotFound) Is(target error) bool {
+	return target == ErrDependencyNotFound
+}
+
+func (err *DependencyNotFound) setRoute(method, path string) {
+	err.Method, err.Path = method, path
+}
+
+// AmbiguousDependency : インタフェース型のフィールドへ注入可能なサービスが複数登録されている場合のエラー型
+// 名前を指定した `inject:"name"` タグを使用すること
+type AmbiguousDependency struct {
+	Message    string
+	Method     string
+	Path       string
+	Ctlname    string
+	Field      string
+	Candidates []string // 該当するサービスの型名
+}
+
+func (err *AmbiguousDependency) Error() string {
+	return err.Message
+}
+
+// Is : ErrAmbiguousDependency と比較した場合 true を返却する
+func (err *AmbiguousDependency) Is(target error) bool {
+	return target == ErrAmbiguousDependency
+}
+
+func (err *AmbiguousDependency) setRoute(method, path string) {
+	err.Method, err.Path = method, path
+}
@@ -0,0 +1,132 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type Database struct{ Name string }
+type Logger interface{ Log(string) string }
+type PrefixLogger struct{ Prefix string }
+
+func (l *PrefixLogger) Log(s string) string { return l.Prefix + s }
+
+type Counter struct{ N int }
+
+type InjectBase struct {
+	Log Logger `inject:""`
+}
+type Inject struct {
+	InjectBase
+	DB      *Database `inject:"db"`
+	Counter *Counter  `inject:"counter"`
+	Cache   *Database `inject:"cache,optional"`
+	Plain   *Database
+}
+
+func (c *Inject) Show() string {
+	return fmt.Sprintf("%s:%s:%d:%v", c.Log.Log("show"), c.DB.Name, c.Counter.N, c.Cache == nil)
+}
+
+func Test__INJECT(t *testing.T) {
+	c := NewContainer()
+	// 関数ではない値、不正な形式の関数を登録した場合はエラーとなる
+	if err := c.Provide("counter", 100); err == nil {
+		t.Fatal("Provide: Error")
+	}
+	if err := c.Provide("counter", func(int) *Counter { return nil }); err == nil {
+		t.Fatal("Provide: Error")
+	}
+	if err := c.Provide("counter", func() (*Counter, int) { return nil, 0 }); err == nil {
+		t.Fatal("Provide: Error")
+	}
+	if err := c.Singleton("db", nil); err == nil {
+		t.Fatal("Singleton: Error")
+	}
+
+	c.Singleton("db", &Database{Name: "main"})
+	c.Singleton("", &PrefixLogger{Prefix: "log:"})
+
+	r := New()
+	r.Container = c
+	r.AddClass(Inject{})
+	r.Register("GET", "/", "Inject.Show")
+
+	// counter が登録されていないため、エラーとなる
+	if _, err := r.Create(); err == nil {
+		t.Fatal("Create: Error")
+	} else if v, ok := err.(*DependencyNotFound); !ok || v.Field != "Counter" {
+		t.Fatal("Create: Error", err)
+	}
+
+	// リクエスト単位のサービスを登録する
+	var n int
+	c.Provide("counter", func() *Counter {
+		n++
+		return &Counter{N: n}
+	})
+	router, err := r.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Create 時点ではリクエスト単位のサービスは生成されない
+	if n != 0 {
+		t.Fatal("Create: Error", n)
+	}
+
+	caller, args, err := router.Caller("GET", "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 2; i++ {
+		result, err := caller.Call(args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := result[0].String(); s != fmt.Sprintf("log:show:main:%d:true", i) {
+			t.Fatal("Call: Error", s)
+		}
+	}
+
+	// 生成関数がエラーを返却した場合、Get はエラーとなる
+	c.Provide("counter", func() (*Counter, error) {
+		return nil, fmt.Errorf("connection refused")
+	})
+	if _, err := caller.Get(); err == nil {
+		t.Fatal("Get: Error")
+	}
+
+	// 型が一致しないサービスが登録されている場合はエラーとなる
+	c.Singleton("db", "main")
+	if _, err := r.Create(); err == nil {
+		t.Fatal("Create: Error")
+	}
+}
+
+type UpperLogger struct{}
+
+func (l UpperLogger) Log(s string) string { return s }
+
+func Test__INJECT_AMBIGUOUS(t *testing.T) {
+	c := NewContainer()
+	c.Singleton("db", &Database{Name: "main"})
+	c.Singleton("counter", &Counter{})
+	c.Singleton("", &PrefixLogger{Prefix: "log:"})
+	c.Singleton("", UpperLogger{})
+
+	r := New()
+	r.Container = c
+	r.AddClass(Inject{})
+	r.Register("GET", "/", "Inject.Show")
+
+	// インタフェースを実装するサービスが複数登録されている場合は、登録順によらずエラーとなる
+	_, err := r.Create()
+	var ambiguous *AmbiguousDependency
+	if !errors.Is(err, ErrAmbiguousDependency) || !errors.As(err, &ambiguous) {
+		t.Fatal("Create: Error", err)
+	}
+	if ambiguous.Field != "Log" || len(ambiguous.Candidates) != 2 || ambiguous.Candidates[0] != "*router.PrefixLogger" {
+		t.Fatal("AmbiguousDependency: Error", ambiguous)
+	}
+}
@@ -0,0 +1,20 @@
+// gen : routes.Table のルートパスから routes_gen.go を出力する
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+
+	"github.com/ochipin/router/internal/routes"
+)
+
+func main() {
+	var b bytes.Buffer
+	if err := routes.Table().Generate(&b, "github.com/ochipin/router/internal/generated"); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("routes_gen.go", b.Bytes(), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,5 @@
+// Package generated : Generate で出力した Generator の使用例
+// routes.Table のルートパスから routes_gen.go を出力する
+package generated
+
+//go:generate go run ./gen
@@ -0,0 +1,115 @@
+package generated
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ochipin/router"
+	"github.com/ochipin/router/internal/routes"
+	"github.com/ochipin/router/internal/sample"
+)
+
+func Test__GENERATE(t *testing.T) {
+	// routes_gen.go が最新であること
+	var b bytes.Buffer
+	if err := routes.Table().Generate(&b, "github.com/ochipin/router/internal/generated"); err != nil {
+		t.Fatal(err)
+	}
+	src, err := os.ReadFile("routes_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b.Bytes(), src) {
+		t.Fatal("routes_gen.go is out of date. run go generate")
+	}
+}
+
+func Test__GENERATE_CALL(t *testing.T) {
+	r := routes.Table()
+	r.Generator = RouteGenerator{}
+	data, err := r.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		path   string
+		result func([]reflect.Value) string
+	}{
+		{"/", func(out []reflect.Value) string { return out[0].String() }},
+		{"/users/10", func(out []reflect.Value) string { return out[0].Interface().(*sample.User).Name }},
+		{"/u/10", func(out []reflect.Value) string { return out[0].Interface().(*sample.User).Name }},
+		{"/users/find/abc", func(out []reflect.Value) string { return out[0].Interface().([]sample.User)[0].Name }},
+		{"/ping", func(out []reflect.Value) string { return fmt.Sprint(len(out)) }},
+	}
+	var results = []string{"sample.Sample:", "user10", "user10", "ABC", "0"}
+	for i, test := range tests {
+		caller, args, err := data.Caller("GET", test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 従来の Action ではなく、生成した型で呼び出す
+		if _, ok := caller.(*router.Action); ok {
+			t.Fatal("Caller: Error", test.path, caller)
+		}
+		out, err := caller.Call(args)
+		if err != nil {
+			t.Fatal(test.path, err)
+		}
+		if result := test.result(out); result != results[i] {
+			t.Fatal("Call: Error", test.path, result)
+		}
+	}
+
+	// 復帰値の型の検証、エラーは従来の Action と同じ結果となる
+	caller, args, _ := data.Caller("GET", "/users/0")
+	out, err := caller.Call(args, "*sample.User", "error")
+	if err != nil || out[1].IsNil() {
+		t.Fatal("Call: Error", err)
+	}
+	if _, err := caller.Call(args, "string"); err == nil {
+		t.Fatal("Call: Error")
+	} else if _, ok := err.(*router.NotEnoughRets); !ok {
+		t.Fatal("Call: Error", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := caller.CallContext(ctx, args); err != context.Canceled {
+		t.Fatal("CallContext: Error", err)
+	}
+
+	// 生成関数、サービス、実行制限時間が設定されている場合も、リフレクションを使用せずに呼び出す
+	// 従来の Action は登録されたコントローラ (Controller) を参照するため、nil とした場合は呼び出せない
+	r.Timeout = time.Second
+	r.Container = router.NewContainer()
+	r.AddConstructor(func() *sample.Sample { return &sample.Sample{Name: "ctor"} })
+	data, err = r.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	caller, args, _ = data.Caller("GET", "/users/10")
+	caller.(route3).Controller = nil
+	if out, err := caller.Call(args); err != nil || out[0].Interface().(*sample.User).ID != 10 {
+		t.Fatal("Call: Error", err)
+	}
+	caller, args, _ = data.Caller("GET", "/")
+	caller.(route0).Controller = nil
+	if out, err := caller.Call(args); err != nil || out[0].String() != "sample.Sample:ctor" {
+		t.Fatal("Call: Error", out, err)
+	}
+
+	// 生成関数のエラーは、従来の Action と同じく内包される
+	errNotConfigured := fmt.Errorf("not configured")
+	r.AddConstructor(func() (*sample.Sample, error) { return nil, errNotConfigured })
+	data, _ = r.Create()
+	caller, args, _ = data.Caller("GET", "/")
+	if _, err := caller.Call(args); !errors.Is(err, errNotConfigured) {
+		t.Fatal("Call: Error", err)
+	}
+}
@@ -0,0 +1,214 @@
+// Code generated by github.com/ochipin/router. DO NOT EDIT.
+
+package generated
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/ochipin/router"
+	sample "github.com/ochipin/router/internal/sample"
+)
+
+// RouteGenerator : 登録されているアクションを、リフレクションを使用せずに呼び出す Generator
+type RouteGenerator struct{}
+
+// Action : ルートID に対応するアクションを生成する
+func (RouteGenerator) Action(ctlname, actname string, controller interface{}) router.Result {
+	action := &router.Action{Ctlname: ctlname, Actname: actname, Controller: controller}
+	switch ctlname + "." + actname {
+	case "Sample.Index":
+		return route0{action}
+	case "Users.Find":
+		return route1{action}
+	case "Users.Ping":
+		return route2{action}
+	case "Users.Show", "sample.Users.Show":
+		return route3{action}
+	}
+	return action
+}
+
+// routeRets : 復帰値の型の指定が無いか、want と一致するか判定する
+func routeRets(ret []string, want ...string) bool {
+	if len(ret) == 0 {
+		return true
+	}
+	if len(ret) != len(want) {
+		return false
+	}
+	for i := range ret {
+		if ret[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// route0 : Sample.Index
+type route0 struct{ *router.Action }
+
+func (r route0) Call(args []reflect.Value, ret ...string) ([]reflect.Value, error) {
+	return r.CallContext(context.Background(), args, ret...)
+}
+
+func (r route0) CallContext(ctx context.Context, args []reflect.Value, ret ...string) ([]reflect.Value, error) {
+	if len(args) != 0 || !routeRets(ret, "string") {
+		return r.Action.CallContext(ctx, args, ret...)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	v, err := r.Instance(new(sample.Sample))
+	if err != nil {
+		return nil, err
+	}
+	c, ok := v.(*sample.Sample)
+	if !ok {
+		return r.Action.CallContext(ctx, args, ret...)
+	}
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	call := func() []reflect.Value {
+		o0 := c.Index()
+		return []reflect.Value{reflect.ValueOf(&o0).Elem()}
+	}
+	if r.Timeout <= 0 {
+		return call(), nil
+	}
+	return r.CallTimeout(ctx, call)
+}
+
+// route1 : Users.Find
+type route1 struct{ *router.Action }
+
+func (r route1) Call(args []reflect.Value, ret ...string) ([]reflect.Value, error) {
+	return r.CallContext(context.Background(), args, ret...)
+}
+
+func (r route1) CallContext(ctx context.Context, args []reflect.Value, ret ...string) ([]reflect.Value, error) {
+	if len(args) != 1 || !routeRets(ret, "[]sample.User") {
+		return r.Action.CallContext(ctx, args, ret...)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	a0, ok := args[0].Interface().(string)
+	if !ok {
+		return r.Action.CallContext(ctx, args, ret...)
+	}
+	v, err := r.Instance(new(sample.Users))
+	if err != nil {
+		return nil, err
+	}
+	c, ok := v.(*sample.Users)
+	if !ok {
+		return r.Action.CallContext(ctx, args, ret...)
+	}
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	call := func() []reflect.Value {
+		o0 := c.Find(a0)
+		return []reflect.Value{reflect.ValueOf(&o0).Elem()}
+	}
+	if r.Timeout <= 0 {
+		return call(), nil
+	}
+	return r.CallTimeout(ctx, call)
+}
+
+// route2 : Users.Ping
+type route2 struct{ *router.Action }
+
+func (r route2) Call(args []reflect.Value, ret ...string) ([]reflect.Value, error) {
+	return r.CallContext(context.Background(), args, ret...)
+}
+
+func (r route2) CallContext(ctx context.Context, args []reflect.Value, ret ...string) ([]reflect.Value, error) {
+	if len(args) != 0 || !routeRets(ret) {
+		return r.Action.CallContext(ctx, args, ret...)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	v, err := r.Instance(new(sample.Users))
+	if err != nil {
+		return nil, err
+	}
+	c, ok := v.(*sample.Users)
+	if !ok {
+		return r.Action.CallContext(ctx, args, ret...)
+	}
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	call := func() []reflect.Value {
+		c.Ping()
+		return []reflect.Value{}
+	}
+	if r.Timeout <= 0 {
+		return call(), nil
+	}
+	return r.CallTimeout(ctx, call)
+}
+
+// route3 : Users.Show, sample.Users.Show
+type route3 struct{ *router.Action }
+
+func (r route3) Call(args []reflect.Value, ret ...string) ([]reflect.Value, error) {
+	return r.CallContext(context.Background(), args, ret...)
+}
+
+func (r route3) CallContext(ctx context.Context, args []reflect.Value, ret ...string) ([]reflect.Value, error) {
+	if len(args) != 1 || !routeRets(ret, "*sample.User", "error") {
+		return r.Action.CallContext(ctx, args, ret...)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	a0, ok := args[0].Interface().(int)
+	if !ok {
+		return r.Action.CallContext(ctx, args, ret...)
+	}
+	v, err := r.Instance(new(sample.Users))
+	if err != nil {
+		return nil, err
+	}
+	c, ok := v.(*sample.Users)
+	if !ok {
+		return r.Action.CallContext(ctx, args, ret...)
+	}
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	call := func() []reflect.Value {
+		o0, o1 := c.Show(ctx, a0)
+		return []reflect.Value{reflect.ValueOf(&o0).Elem(), reflect.ValueOf(&o1).Elem()}
+	}
+	if r.Timeout <= 0 {
+		return call(), nil
+	}
+	return r.CallTimeout(ctx, call)
+}
@@ -0,0 +1,20 @@
+// Package routes : Generate の使用例で使用するルーティングテーブル
+package routes
+
+import (
+	"github.com/ochipin/router"
+	"github.com/ochipin/router/internal/sample"
+)
+
+// Table : ルーティングテーブルを生成する
+func Table() *router.RouteTable {
+	r := router.New()
+	r.AddClass(sample.Sample{})
+	r.AddClass(sample.Users{})
+	r.Register("GET", "/", "Sample.Index")
+	r.Register("GET", "/users/:id<int>", "Users.Show")
+	r.Register("GET", "/u/:id<int>", "sample.Users.Show")
+	r.Register("GET", "/users/find/{name}", "Users.Find")
+	r.Register("GET", "/ping", "Users.Ping")
+	return r
+}
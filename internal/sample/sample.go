@@ -0,0 +1,44 @@
+// Package sample : ルーティングのテストで使用する、他パッケージのコントローラ
+package sample
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Sample : router.Sample と同名のコントローラ
+type Sample struct {
+	Name string
+}
+
+// Index : 名前を返却する
+func (s *Sample) Index() string { return "sample.Sample:" + s.Name }
+
+// User : Users コントローラが返却する値
+type User struct {
+	ID   int
+	Name string
+}
+
+// Users : 引数、復帰値を伴うアクションを持つコントローラ
+type Users struct{}
+
+// Show : ID に対応するユーザを返却する
+func (c *Users) Show(ctx context.Context, id int) (*User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if id <= 0 {
+		return nil, fmt.Errorf("user %d not found", id)
+	}
+	return &User{ID: id, Name: fmt.Sprintf("user%d", id)}, nil
+}
+
+// Find : 名前に一致するユーザを返却する
+func (c Users) Find(name string) []User {
+	return []User{{Name: strings.ToUpper(name)}}
+}
+
+// Ping : 引数、復帰値を持たない
+func (c *Users) Ping() {}
@@ -0,0 +1,85 @@
+package router
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Meta : ルートパスへ付与するメタデータ
+// ミドルウェアやドキュメント生成で、ルート単位の判定に使用する
+type Meta struct {
+	Name        string            `json:"name,omitempty"`        // ルート名
+	Description string            `json:"description,omitempty"` // ルートの説明
+	Tags        []string          `json:"tags,omitempty"`        // 分類用のタグ
+	Auth        []string          `json:"auth,omitempty"`        // 必要な認証、権限 (ex: "login", "admin")
+	RateLimit   string            `json:"rateLimit,omitempty"`   // レート制限の分類 (ex: "strict")
+	Deprecated  bool              `json:"deprecated,omitempty"`  // 非推奨のルート
+	Extra       map[string]string `json:"extra,omitempty"`       // 任意の値
+}
+
+// HasTag : 指定したタグが付与されているか判定する
+func (meta *Meta) HasTag(tag string) bool {
+	return meta != nil && containsString(meta.Tags, tag)
+}
+
+// row : TableList で出力する形式へ変換する
+func (meta *Meta) row() []string {
+	var extra []string
+	for k, v := range meta.Extra {
+		extra = append(extra, k+"="+v)
+	}
+	sort.Strings(extra)
+	var deprecated string
+	if meta.Deprecated {
+		deprecated = strconv.FormatBool(meta.Deprecated)
+	}
+	return []string{
+		meta.Name, meta.Description, strings.Join(meta.Tags, ","), strings.Join(meta.Auth, ","),
+		meta.RateLimit, deprecated, strings.Join(extra, ","),
+	}
+}
+
+// RegisterMeta : メタデータ付きでルートパスを登録する。Name を指定する場合は、すべてのルートパスで一意であること
+// 同じアクションで登録済みのルートパスの場合は、実行制限時間、既定値を保持したままメタデータを置き換える
+// ex) r.RegisterMeta("GET", "/users/:id", "Users.Show", router.Meta{Name: "user", Auth: []string{"login"}})
+func (rt *RouteTable) RegisterMeta(method, path, name string, meta Meta) error {
+	if err := rt.checkName(method, path, meta.Name); err != nil {
+		return err
+	}
+	if err := rt.Register(method, path, name); err != nil {
+		return err
+	}
+	rt.routes[method][path].meta = &meta
+	return nil
+}
+
+// GetMeta : 登録されているルートパスのメタデータを返却する。メタデータが存在しない場合は nil を返却する
+func (rt *RouteTable) GetMeta(method, path string) *Meta {
+	if rt.routes == nil {
+		return nil
+	}
+	route, ok := rt.routes[method][path]
+	if !ok {
+		return nil
+	}
+	return route.meta
+}
+
+// setMeta : メタデータを設定可能なアクションの場合、メタデータを設定する
+func setMeta(action Result, meta *Meta) {
+	if meta == nil {
+		return
+	}
+	if setter, ok := action.(interface{ SetMeta(*Meta) }); ok {
+		setter.SetMeta(meta)
+	}
+}
+
+// MetaOf : Router.Caller で取得したアクションのメタデータを返却する。メタデータが存在しない場合は nil を返却する
+func MetaOf(result Result) *Meta {
+	if v, ok := result.(interface{ Metadata() *Meta }); ok {
+		return v.Metadata()
+	}
+	return nil
+}
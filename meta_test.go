@@ -0,0 +1,170 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test__META(t *testing.T) {
+	r := New()
+	r.AddRegexp("id", "([0-9]+)")
+	r.AddClass(Users{})
+	r.Register("GET", "/users", "Users.Index")
+	meta := Meta{
+		Name:        "user",
+		Description: "Show user",
+		Tags:        []string{"users", "public"},
+		Auth:        []string{"login"},
+		RateLimit:   "strict",
+		Deprecated:  true,
+		Extra:       map[string]string{"owner": "team-a", "cache": "60s"},
+	}
+	if err := r.RegisterMeta("GET", "/users/:id", "Users.Show", meta); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RegisterMeta("GET", "", "Users.Show", meta); err == nil {
+		t.Fatal("RegisterMeta: Error")
+	}
+	if m := r.GetMeta("GET", "/users/:id"); m == nil || m.Name != "user" || !m.HasTag("public") {
+		t.Fatal("GetMeta: Error", m)
+	}
+	if r.GetMeta("GET", "/users") != nil || r.GetMeta("POST", "/users") != nil {
+		t.Fatal("GetMeta: Error")
+	}
+
+	// Caller で取得したアクションからメタデータを参照可能
+	for _, create := range []func() (Router, error){r.Create, r.CreateDry} {
+		router, err := create()
+		if err != nil {
+			t.Fatal(err)
+		}
+		action, _, err := router.Caller("GET", "/users/10")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m := MetaOf(action); m == nil || !reflect.DeepEqual(*m, meta) {
+			t.Fatal("MetaOf: Error", m)
+		}
+		action, _, _ = router.Caller("GET", "/users")
+		if m := MetaOf(action); m != nil || m.HasTag("users") {
+			t.Fatal("MetaOf: Error", m)
+		}
+	}
+
+	// メタデータが存在しない場合も META を出力する
+	if list := New().TableList(); list["META"] == nil || len(list["META"]) != 0 {
+		t.Fatal("TableList: Error", list)
+	}
+
+	// TableList は、メタデータが付与されたルートのみ出力する
+	list := r.TableList()
+	want := [][]string{{"GET", "/users/:id", "user", "Show user", "users,public", "login", "strict", "true", "cache=60s,owner=team-a"}}
+	if !reflect.DeepEqual(list["META"], want) {
+		t.Fatal("TableList: Error", list["META"])
+	}
+
+	// JSON 形式で出力、読み込みが可能
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"meta":{"name":"user"`) {
+		t.Fatal("MarshalJSON: Error", string(data))
+	}
+	loaded := New()
+	if err := json.Unmarshal(data, loaded); err != nil {
+		t.Fatal(err)
+	}
+	if m := loaded.GetMeta("GET", "/users/:id"); m == nil || !reflect.DeepEqual(*m, meta) {
+		t.Fatal("UnmarshalJSON: Error", m)
+	}
+
+	// 読み込み時もルート名の重複を検証する
+	duplicated := `{"routes":[` +
+		`{"method":"GET","path":"/a","action":"Users.Index","meta":{"name":"user"}},` +
+		`{"method":"GET","path":"/b","action":"Users.Index","meta":{"name":"user"}}]}`
+	if err := json.Unmarshal([]byte(duplicated), New()); !errors.Is(err, ErrDuplicateName) {
+		t.Fatal("UnmarshalJSON: Error", err)
+	}
+}
+
+func Test__META_OPENAPI(t *testing.T) {
+	r := New()
+	r.AddClass(Users{})
+	r.Register("GET", "/users", "Users.Index")
+	r.RegisterMeta("GET", "/users/:id<int>", "Users.Show", Meta{Name: "showUser", Description: "Show user", Tags: []string{"users"}, Deprecated: true})
+	r.RegisterMeta("DELETE", "/users/:id<int>", "Users.Delete", Meta{Auth: []string{"admin"}})
+	doc, err := r.OpenAPI("Users API", "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// メタデータが付与されている場合は、ルート名、説明、タグを使用する
+	op := doc.Paths["/users/{id}"]["get"]
+	if op.OperationID != "showUser" || op.Summary != "Show user" || !reflect.DeepEqual(op.Tags, []string{"users"}) || !op.Deprecated {
+		t.Fatal("OpenAPI: Error", op)
+	}
+	// 付与されていない項目は、既定値とする
+	op = doc.Paths["/users/{id}"]["delete"]
+	if op.OperationID != "Users.Delete" || op.Summary != "Users.Delete" || !reflect.DeepEqual(op.Tags, []string{"Users"}) || op.Deprecated {
+		t.Fatal("OpenAPI: Error", op)
+	}
+}
+
+func Test__META_COMBINE(t *testing.T) {
+	// 実行制限時間、既定値、メタデータは、登録順に関わらず同じルートパスへ併せて設定可能
+	r := New()
+	r.AddClass(Users{})
+	r.RegisterTimeout("GET", "/users(/:id<int>)", "Users.Show", time.Second)
+	r.RegisterMeta("GET", "/users(/:id<int>)", "Users.Show", Meta{Name: "user", Description: "Show user"})
+	r.RegisterDefaults("GET", "/users(/:id<int>)", "Users.Show", map[string]interface{}{"id": 7})
+	r.RegisterName("GET", "/users(/:id<int>)", "Users.Show", "member")
+	r.RegisterDefaults("GET", "/members(/:id<int>)", "Users.Show", map[string]interface{}{"id": 8})
+	r.RegisterTimeout("GET", "/members(/:id<int>)", "Users.Show", 2*time.Second)
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := New()
+	if err := json.Unmarshal(data, loaded); err != nil {
+		t.Fatal(err)
+	}
+	loaded.AddClass(Users{})
+
+	for _, table := range []*RouteTable{r, loaded} {
+		if m := table.GetMeta("GET", "/users(/:id<int>)"); m == nil || m.Name != "member" || m.Description != "Show user" {
+			t.Fatal("GetMeta: Error", m)
+		}
+		router, err := table.Create()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, test := range []struct {
+			path    string
+			id      int
+			timeout time.Duration
+		}{
+			{"/users", 7, time.Second},
+			{"/members", 8, 2 * time.Second},
+		} {
+			action, args, err := router.Caller("GET", test.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(args) != 1 || args[0].Interface() != test.id || action.(*Action).Timeout != test.timeout {
+				t.Fatal("Caller: Error", test.path, args, action.(*Action).Timeout)
+			}
+		}
+	}
+
+	// 異なるアクションを登録した場合は置き換える
+	r.Register("GET", "/users(/:id<int>)", "Users.Find")
+	if r.GetMeta("GET", "/users(/:id<int>)") != nil {
+		t.Fatal("Register: Error")
+	}
+}
@@ -0,0 +1,176 @@
+package router
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Mount : 別途構築した RouteTable のルートパスを、prefix 配下のルートパスとして登録する
+// コントローラ名、ルート名には名前空間 ns を付与し (ex: billing:Billing.Index)、コントローラ、正規表現は sub に登録されたものを使用する。
+// そのため、sub のコントローラ名、正規表現名が rt と重複しても衝突しない。
+// リダイレクト先、別名の参照先のパスも prefix 配下のパスとする。
+// sub.Timeout は、sub のルートパスのうち実行制限時間が未指定のものへ適用する (ルートパスのグループ単位の制限時間)。
+// sub.Container は、sub のコントローラへ注入するサービスとして使用する。nil の場合は rt.Container を使用する。
+// アクションは rt.Generator で生成するため、sub.Generator は既定の Generator であること。
+// ルートパスは Mount 時点の内容を登録するため、Mount 後に sub へ登録したルートパスは反映されない
+// ex) r.Mount("billing", "/billing", billing.Table())
+func (rt *RouteTable) Mount(ns, prefix string, sub *RouteTable) error {
+	if !validNamespace(ns) {
+		return &InvalidArgument{Message: fmt.Sprintf("'%s' - invalid namespace", ns)}
+	}
+	if !strings.HasPrefix(prefix, "/") || strings.HasSuffix(prefix, "/") || strings.ContainsAny(prefix, reserved) {
+		return &InvalidPath{Message: fmt.Sprintf("'%s' - invalid mount prefix", prefix), Path: prefix}
+	}
+	if sub == nil || sub == rt {
+		return &InvalidArgument{Message: fmt.Sprintf("'%s' - invalid route table", ns)}
+	}
+	if sub.Generator != nil && sub.Generator != Generator(sub) {
+		return &InvalidArgument{Message: fmt.Sprintf("'%s' - mounted route table must use the default generator", ns)}
+	}
+	if _, ok := rt.mounts[ns]; ok {
+		return &InvalidArgument{Message: fmt.Sprintf("'%s' - namespace already mounted", ns)}
+	}
+	rt.init()
+
+	// 登録するルートパスを求め、既存のルートパス、ルート名と重複しないか検証する
+	type entry struct {
+		method, path string
+		route        *Route
+	}
+	var entries []entry
+	for method, routes := range sub.routes {
+		for path, route := range routes {
+			full := mountPath(prefix, path)
+			if _, ok := rt.routes[method][full]; ok {
+				return &RouteConflict{
+					Message: fmt.Sprintf("'[%s]: %s' - conflicting routes", method, full),
+					Method:  method,
+					Paths:   []string{full},
+				}
+			}
+			r := *route
+			r.ctlname = qualify(ns, route.ctlname)
+			if r.timeout == 0 {
+				r.timeout = sub.Timeout
+			}
+			// リダイレクト先、別名の参照先も prefix 配下のパスとする
+			if route.redirect != "" {
+				r.redirect = mountPath(prefix, route.redirect)
+			}
+			if route.alias != "" {
+				r.alias = mountPath(prefix, route.alias)
+			}
+			if route.meta != nil {
+				meta := *route.meta
+				if meta.Name != "" {
+					meta.Name = qualify(ns, meta.Name)
+					if err := rt.checkName(method, full, meta.Name); err != nil {
+						return err
+					}
+				}
+				r.meta = &meta
+			}
+			entries = append(entries, entry{method, full, &r})
+		}
+	}
+
+	for _, e := range entries {
+		if _, ok := rt.routes[e.method]; !ok {
+			rt.routes[e.method] = make(map[string]*Route)
+		}
+		rt.routes[e.method][e.path] = e.route
+	}
+	rt.mounts[ns] = sub
+	return nil
+}
+
+// mountPath : prefix 配下のパスを返却する。path が "/" の場合は prefix とする
+func mountPath(prefix, path string) string {
+	if path == "/" {
+		return prefix
+	}
+	return prefix + path
+}
+
+// qualify : 名前へ名前空間を付与する。既に名前空間が付与されている場合は、名前空間を入れ子にする
+// ex) qualify("billing", "Billing") // billing:Billing
+// ex) qualify("billing", "admin:Users") // billing.admin:Users
+func qualify(ns, name string) string {
+	if strings.Contains(name, ":") {
+		return ns + "." + name
+	}
+	return ns + ":" + name
+}
+
+// splitNamespace : 名前を名前空間と、名前空間を除いた名前へ分割する
+func splitNamespace(name string) (string, string) {
+	idx := strings.LastIndex(name, ":")
+	if idx == -1 {
+		return "", name
+	}
+	return name[:idx], name[idx+1:]
+}
+
+// validNamespace : 名前空間として使用可能な名前か検証する
+func validNamespace(ns string) bool {
+	return ns != "" && strings.IndexFunc(ns, func(r rune) bool { return r > 0x7f || !isNameChar(byte(r)) }) == -1
+}
+
+// Mounted : 名前空間に該当する、マウントされた RouteTable を返却する。存在しない場合は nil を返却する
+// 入れ子の名前空間は '.' で区切る。JSON 形式から読み込んだ場合は、返却された RouteTable へコントローラを登録する
+// ex) r.Mounted("billing.admin").AddClass(admin.Users{})
+func (rt *RouteTable) Mounted(ns string) *RouteTable {
+	t := rt
+	for _, name := range strings.Split(ns, ".") {
+		if t = t.mounts[name]; t == nil {
+			return nil
+		}
+	}
+	return t
+}
+
+// scope : コントローラ名の名前空間に該当する、コントローラ、正規表現を参照する RouteTable を返却する
+// パスの照合方法などの設定は rt に従う。名前空間が無い場合は rt を返却する
+func (rt *RouteTable) scope(ctlname string) *RouteTable {
+	ns, _ := splitNamespace(ctlname)
+	if ns == "" {
+		return rt
+	}
+	s := *rt
+	s.regex, s.classes, s.factories = nil, nil, nil
+	if sub := rt.Mounted(ns); sub != nil {
+		s.regex, s.classes, s.factories = sub.regex, sub.classes, sub.factories
+		if sub.Container != nil {
+			s.Container = sub.Container
+		}
+	}
+	return &s
+}
+
+// mountTable : 名前空間に該当する RouteTable を返却する。存在しない場合は、空の RouteTable をマウントする
+func (rt *RouteTable) mountTable(ns string) (*RouteTable, error) {
+	t := rt
+	for _, name := range strings.Split(ns, ".") {
+		if !validNamespace(name) {
+			return nil, &InvalidArgument{Message: fmt.Sprintf("'%s' - invalid namespace", ns)}
+		}
+		t.init()
+		sub, ok := t.mounts[name]
+		if !ok {
+			sub = New()
+			t.mounts[name] = sub
+		}
+		t = sub
+	}
+	return t, nil
+}
+
+// eachMount : マウントされた RouteTable を、入れ子を含めて名前空間と共に処理する
+func (rt *RouteTable) eachMount(fn func(ns string, sub *RouteTable)) {
+	for name, sub := range rt.mounts {
+		fn(name, sub)
+		sub.eachMount(func(ns string, nested *RouteTable) {
+			fn(name+"."+ns, nested)
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/ochipin/router/internal/sample"
+)
+
+func Test__MOUNT(t *testing.T) {
+	// サブモジュールのルーティングテーブルを、ホストとは別に構築する
+	sub := New()
+	sub.AddRegexp("id", "([a-z]+)")
+	sub.AddPrototype(&sample.Sample{Name: "billing"})
+	sub.Register("GET", "/", "Sample.Index")
+	sub.RegisterName("GET", "/invoices/:id", "Sample.Index", "invoice")
+
+	r := New()
+	r.AddRegexp("id", "([0-9]+)")
+	r.AddClass(Sample{})
+	r.Register("GET", "/", "Sample.Index")
+	r.Register("GET", "/users/:id", "Sample.TheTest")
+	if err := r.Mount("billing", "/billing", sub); err != nil {
+		t.Fatal(err)
+	}
+
+	// コントローラ名、正規表現名が重複しても、それぞれのテーブルのものを使用する
+	router, err := r.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	action, _, err := router.Caller("GET", "/billing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := action.Call(nil)
+	if err != nil || out[0].String() != "sample.Sample:billing" {
+		t.Fatal("Call: Error", out, err)
+	}
+	if ctlname, actname := action.Name(); ctlname != "billing:Sample" || actname != "Index" {
+		t.Fatal("Name: Error", ctlname, actname)
+	}
+	if _, args, err := router.Caller("GET", "/billing/invoices/abc"); err != nil || args[0].String() != "abc" {
+		t.Fatal("Caller: Error", args, err)
+	}
+	if _, _, err := router.Caller("GET", "/billing/invoices/10"); err == nil {
+		t.Fatal("Caller: Error")
+	}
+	if _, _, err := router.Caller("GET", "/users/10"); err != nil {
+		t.Fatal(err)
+	}
+
+	// ルート名には名前空間が付与される
+	if path, err := r.PathFor("billing:invoice", map[string]interface{}{"id": "abc"}); err != nil || path != "/billing/invoices/abc" {
+		t.Fatal("PathFor: Error", path, err)
+	}
+	if _, err := r.PathFor("billing:invoice", map[string]interface{}{"id": 10}); err == nil {
+		t.Fatal("PathFor: Error")
+	}
+
+	// TableList は、マウントしたルートパス、正規表現を含めて出力する
+	list := r.TableList()
+	if len(list["ROUTER"]) != 4 || len(list["REGEXP"]) != 2 {
+		t.Fatal("TableList: Error", list)
+	}
+	if r.GetRouter("GET", "/billing/invoices/:id") != "billing:Sample.Index" {
+		t.Fatal("GetRouter: Error", list["ROUTER"])
+	}
+	found := false
+	for _, row := range list["REGEXP"] {
+		found = found || reflect.DeepEqual(row, []string{"billing:id", "([a-z]+)"})
+	}
+	if !found || len(r.Lint()) != 0 {
+		t.Fatal("TableList: Error", list["REGEXP"], r.Lint())
+	}
+}
+
+func Test__MOUNT_NESTED(t *testing.T) {
+	admin := New()
+	admin.AddClass(Sample{})
+	admin.Register("GET", "/hello", "Sample.World")
+
+	sub := New()
+	sub.AddClass(sample.Sample{})
+	sub.Register("GET", "/", "Sample.Index")
+	if err := sub.Mount("admin", "/admin", admin); err != nil {
+		t.Fatal(err)
+	}
+
+	r := New()
+	if err := r.Mount("billing", "/billing", sub); err != nil {
+		t.Fatal(err)
+	}
+	if r.GetRouter("GET", "/billing/admin/hello") != "billing.admin:Sample.World" {
+		t.Fatal("Mount: Error", r.TableList())
+	}
+	router, err := r.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := router.Caller("GET", "/billing/admin/hello"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test__MOUNT_ERROR(t *testing.T) {
+	sub := New()
+	sub.Register("GET", "/", "Sample.Index")
+
+	r := New()
+	r.Register("GET", "/billing", "Sample.Index")
+	for _, prefix := range []string{"", "billing", "/billing/", "/:billing"} {
+		if err := r.Mount("billing", prefix, sub); !errors.Is(err, ErrInvalidPath) {
+			t.Fatal("Mount: Error", prefix, err)
+		}
+	}
+	for _, ns := range []string{"", "bill.ing", "bill:ing"} {
+		if err := r.Mount(ns, "/billing", sub); err == nil {
+			t.Fatal("Mount: Error", ns)
+		}
+	}
+	if err := r.Mount("billing", "/billing", nil); err == nil {
+		t.Fatal("Mount: Error")
+	}
+
+	// 既存のルートパスと重複する場合は、何も登録しない
+	var conflict *RouteConflict
+	if err := r.Mount("billing", "/billing", sub); !errors.As(err, &conflict) {
+		t.Fatal("Mount: Error", err)
+	}
+	if len(r.TableList()["ROUTER"]) != 1 {
+		t.Fatal("Mount: Error", r.TableList())
+	}
+	if err := r.Mount("billing", "/pay", sub); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Mount("billing", "/billing2", sub); err == nil {
+		t.Fatal("Mount: Error")
+	}
+
+	// 既定以外の Generator は使用できない
+	custom := New()
+	custom.Generator = r
+	if err := r.Mount("custom", "/custom", custom); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatal("Mount: Error", err)
+	}
+
+	// マウントしていない名前空間のコントローラは、未登録のエラーとなる
+	r = New()
+	r.Register("GET", "/", "billing:Sample.Index")
+	if _, err := r.Create(); !errors.Is(err, ErrControllerNotRegistered) {
+		t.Fatal("Create: Error", err)
+	}
+}
+
+func Test__MOUNT_OPENAPI(t *testing.T) {
+	sub := New()
+	sub.AddRegexp("id", "([a-z]+)")
+	sub.AddClass(sample.Sample{})
+	sub.Register("GET", "/invoices/:id", "Sample.Index")
+
+	r := New()
+	r.Mount("billing", "/billing", sub)
+	doc, err := r.OpenAPI("Billing API", "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := doc.Paths["/billing/invoices/{id}"]["get"]
+	if op == nil || op.OperationID != "billing:Sample.Index" || op.Parameters[0].Schema.Pattern != "^([a-z]+)$" {
+		t.Fatal("OpenAPI: Error", doc.Paths)
+	}
+}
+
+func Test__MOUNT_CONTAINER(t *testing.T) {
+	c := NewContainer()
+	c.Singleton("db", &Database{Name: "billing"})
+	c.Singleton("", &PrefixLogger{Prefix: "log:"})
+	c.Provide("counter", func() *Counter { return &Counter{N: 1} })
+
+	// sub.Container は、sub のコントローラへ注入する
+	sub := New()
+	sub.Container = c
+	sub.AddClass(Inject{})
+	sub.Register("GET", "/", "Inject.Show")
+
+	r := New()
+	if err := r.Mount("billing", "/billing", sub); err != nil {
+		t.Fatal(err)
+	}
+	router, err := r.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	action, args, err := router.Caller("GET", "/billing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out, err := action.Call(args); err != nil || out[0].String() != "log:show:billing:1:true" {
+		t.Fatal("Call: Error", out, err)
+	}
+}
+
+func Test__MOUNT_JSON(t *testing.T) {
+	admin := New()
+	admin.AddRegexp("name", "([a-z]+)")
+	admin.AddClass(Sample{})
+	admin.Register("GET", "/users/:name", "Sample.TheTest")
+
+	sub := New()
+	sub.AddRegexp("id", "([a-z]+)")
+	sub.AddClass(Sample{})
+	sub.RegisterName("GET", "/invoices/:id", "Sample.TheTest", "invoice")
+	sub.RegisterRedirect("GET", "/i/:id", "/invoices/:id", http.StatusMovedPermanently)
+	sub.RegisterAlias("GET", "/bills/:id", "/invoices/:id")
+	sub.Mount("admin", "/admin", admin)
+
+	r := New()
+	r.AddRegexp("id", "([0-9]+)")
+	if err := r.Mount("billing", "/billing", sub); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// マウントした RouteTable の正規表現、名前空間を復元する
+	loaded := New()
+	if err := json.Unmarshal(data, loaded); err != nil {
+		t.Fatal(err)
+	}
+	if again, err := json.Marshal(loaded); err != nil || string(again) != string(data) {
+		t.Fatal("UnmarshalJSON: Error", string(again), string(data))
+	}
+	if loaded.Mounted("billing") == nil || loaded.Mounted("billing.admin") == nil || loaded.Mounted("admin") != nil {
+		t.Fatal("Mounted: Error")
+	}
+	if path, err := loaded.PathFor("billing:invoice", map[string]interface{}{"id": "abc"}); err != nil || path != "/billing/invoices/abc" {
+		t.Fatal("PathFor: Error", path, err)
+	}
+
+	// コントローラは Mounted で取得した RouteTable へ登録する
+	loaded.Mounted("billing").AddClass(Sample{})
+	loaded.Mounted("billing.admin").AddClass(Sample{})
+	router, err := loaded.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/billing/invoices/abc", "/billing/bills/abc", "/billing/admin/users/abc"} {
+		if _, args, err := router.Caller("GET", path); err != nil || args[0].String() != "abc" {
+			t.Fatal("Caller: Error", path, args, err)
+		}
+	}
+	if action, _, err := router.Caller("GET", "/billing/i/abc"); err != nil || action.(*Redirect).Location != "/billing/invoices/abc" {
+		t.Fatal("Caller: Error", action, err)
+	}
+
+	// 不正な名前空間は読み込めない
+	if err := json.Unmarshal([]byte(`{"routes":[],"mounts":[{"namespace":"bill:ing"}]}`), New()); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatal("UnmarshalJSON: Error", err)
+	}
+}
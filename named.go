@@ -0,0 +1,102 @@
+package router
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RouteInfo : 登録されているルートパスの情報
+type RouteInfo struct {
+	Method string
+	Path   string
+	Action string // Controller.Action 形式の名前
+	Meta   *Meta
+}
+
+// RegisterName : ルート名付きでルートパスを登録する。ルート名は、すべてのルートパスで一意であること
+// 同じアクションを複数のパスへ登録した場合でも、ルート名でパスを特定可能
+// 同じアクションで登録済みのルートパスの場合は、メタデータのうちルート名のみを置き換える
+// ex) r.RegisterName("GET", "/users/:id", "Users.Show", "user")
+func (rt *RouteTable) RegisterName(method, path, name, routename string) error {
+	meta := Meta{Name: routename}
+	if route, ok := rt.routes[method][path]; ok && route.meta != nil && route.action() == name {
+		meta = *route.meta
+		meta.Name = routename
+	}
+	return rt.RegisterMeta(method, path, name, meta)
+}
+
+// Route : ルート名に該当するルートパスの情報を返却する
+func (rt *RouteTable) Route(routename string) (RouteInfo, bool) {
+	method, path, route := rt.named(routename)
+	if route == nil {
+		return RouteInfo{}, false
+	}
+	return RouteInfo{
+		Method: method,
+		Path:   path,
+		Action: route.action(),
+		Meta:   route.meta,
+	}, true
+}
+
+// named : ルート名に該当するルートパスのメソッド、パス、Route を返却する。存在しない場合は nil を返却する
+func (rt *RouteTable) named(routename string) (string, string, *Route) {
+	if routename == "" {
+		return "", "", nil
+	}
+	for method, routes := range rt.routes {
+		for path, route := range routes {
+			if route.meta != nil && route.meta.Name == routename {
+				return method, path, route
+			}
+		}
+	}
+	return "", "", nil
+}
+
+// Routes : ルート名が付与されているルートパスの情報を、ルート名の順に返却する
+func (rt *RouteTable) Routes() []RouteInfo {
+	var list []RouteInfo
+	for method, routes := range rt.routes {
+		for path, route := range routes {
+			if route.meta != nil && route.meta.Name != "" {
+				list = append(list, RouteInfo{
+					Method: method,
+					Path:   path,
+					Action: route.action(),
+					Meta:   route.meta,
+				})
+			}
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Meta.Name < list[j].Meta.Name
+	})
+	return list
+}
+
+// PathFor : ルート名に該当するルートパスへ、パラメータの値を埋め込んだパスを返却する
+// ex) r.PathFor("user", map[string]interface{}{"id": 1}) // /users/1
+func (rt *RouteTable) PathFor(routename string, params map[string]interface{}) (string, error) {
+	_, path, route := rt.named(routename)
+	if route == nil {
+		return "", fmt.Errorf("'%s' - route name %w", routename, ErrNotFound)
+	}
+	// 正規表現は、ルートパスの名前空間に該当する RouteTable のものを使用する
+	return rt.scope(route.ctlname).BuildPath(path, params)
+}
+
+// checkName : ルート名が、他のルートパスで使用されていないか検証する
+func (rt *RouteTable) checkName(method, path, routename string) error {
+	info, ok := rt.Route(routename)
+	if !ok || (info.Method == method && info.Path == path) {
+		return nil
+	}
+	return &DuplicateName{
+		Message: fmt.Sprintf("'%s' - route name already used by '[%s]: %s'", routename, info.Method, info.Path),
+		Name:    routename,
+		Method:  info.Method,
+		Path:    info.Path,
+	}
+}
@@ -0,0 +1,98 @@
+package router
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test__NAMED(t *testing.T) {
+	r := New()
+	r.AddRegexp("id", "([0-9]+)")
+	// 同じアクションを複数のパスへ登録する
+	if err := r.RegisterName("GET", "/users/:id", "Users.Show", "user"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RegisterName("GET", "/members/:id", "Users.Show", "member"); err != nil {
+		t.Fatal(err)
+	}
+	r.Register("GET", "/users", "Users.Index")
+
+	info, ok := r.Route("member")
+	if !ok || info.Method != "GET" || info.Path != "/members/:id" || info.Action != "Users.Show" {
+		t.Fatal("Route: Error", info)
+	}
+	if _, ok := r.Route("users"); ok {
+		t.Fatal("Route: Error")
+	}
+	if _, ok := r.Route(""); ok {
+		t.Fatal("Route: Error")
+	}
+	if list := r.Routes(); len(list) != 2 || list[0].Meta.Name != "member" || list[1].Meta.Name != "user" {
+		t.Fatal("Routes: Error", list)
+	}
+
+	// ルート名からパスを生成する
+	if path, err := r.PathFor("user", map[string]interface{}{"id": 10}); err != nil || path != "/users/10" {
+		t.Fatal("PathFor: Error", path, err)
+	}
+	if path, err := r.PathFor("member", map[string]interface{}{"id": 10}); err != nil || path != "/members/10" {
+		t.Fatal("PathFor: Error", path, err)
+	}
+	if _, err := r.PathFor("undefined", nil); !errors.Is(err, ErrNotFound) {
+		t.Fatal("PathFor: Error", err)
+	}
+	if _, err := r.PathFor("user", map[string]interface{}{"id": "abc"}); err == nil {
+		t.Fatal("PathFor: Error")
+	}
+
+	// ルート名は一意であること。同じルートパスへの再登録は可能
+	err := r.RegisterName("POST", "/users", "Users.Create", "user")
+	var dup *DuplicateName
+	if !errors.Is(err, ErrDuplicateName) || !errors.As(err, &dup) || dup.Method != "GET" || dup.Path != "/users/:id" {
+		t.Fatal("RegisterName: Error", err)
+	}
+	if r.GetRouter("POST", "/users") != "" {
+		t.Fatal("RegisterName: Error")
+	}
+	if err := r.RegisterName("GET", "/users/:id", "Users.Detail", "user"); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := r.Route("user"); info.Action != "Users.Detail" {
+		t.Fatal("Route: Error", info)
+	}
+}
+
+func Test__NAMED_LOAD(t *testing.T) {
+	r := New()
+	err := r.Load(strings.NewReader(`
+REGEXP id ([0-9]+)
+GET    /users/:id Users.Show user
+GET    /me        Users.Show
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, ok := r.Route("user"); !ok || info.Path != "/users/:id" {
+		t.Fatal("Load: Error", info)
+	}
+
+	// 重複したルート名は、行番号付きのエラーとなる
+	err = New().Load(strings.NewReader("GET /a A.Index a\nGET /b B.Index a"))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Fatal("Load: Error", err)
+	}
+	for _, text := range []string{"REGEXP id ([0-9]+) id", "GET /a A.Index a b"} {
+		if err := New().Load(strings.NewReader(text)); err == nil || !strings.HasPrefix(err.Error(), "line 1:") {
+			t.Fatal("Load: Error", text, err)
+		}
+	}
+
+	// JSON 形式で重複したルート名は、エラーとなる
+	data := `{"regexp": {}, "routes": [
+		{"method": "GET", "path": "/a", "action": "A.Index", "meta": {"name": "a"}},
+		{"method": "GET", "path": "/b", "action": "B.Index", "meta": {"name": "a"}}]}`
+	if err := New().Load(strings.NewReader(data)); !errors.Is(err, ErrDuplicateName) {
+		t.Fatal("Load: Error", err)
+	}
+}
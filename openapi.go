@@ -0,0 +1,419 @@
+package router
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OpenAPI : OpenAPI 3 形式のドキュメント
+type OpenAPI struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+	Components *OpenAPIComponents                      `json:"components,omitempty"`
+}
+
+// OpenAPIInfo : API の概要
+type OpenAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// OpenAPIOperation : パス、メソッド単位の操作
+type OpenAPIOperation struct {
+	Summary     string                      `json:"summary,omitempty"`
+	OperationID string                      `json:"operationId"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Parameters  []*OpenAPIParameter         `json:"parameters,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+	Deprecated  bool                        `json:"deprecated,omitempty"`
+}
+
+// OpenAPIParameter : パスパラメータ
+type OpenAPIParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required"`
+	Schema   *OpenAPISchema `json:"schema"`
+}
+
+// OpenAPIResponse : 操作の応答
+type OpenAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIMediaType : 応答の形式
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `json:"schema"`
+}
+
+// OpenAPIComponents : ドキュメント内で参照される構造体のスキーマ
+type OpenAPIComponents struct {
+	Schemas map[string]*OpenAPISchema `json:"schemas"`
+}
+
+// OpenAPISchema : 値の型情報
+type OpenAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Pattern              string                    `json:"pattern,omitempty"`
+	Minimum              *int                      `json:"minimum,omitempty"`
+	Items                *OpenAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+}
+
+// openapiMethods : OpenAPI で使用可能なメソッド
+var openapiMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true,
+	"options": true, "head": true, "patch": true, "trace": true,
+}
+
+// constraintFormats : 組み込みの型制約に対応する format
+var constraintFormats = map[string]string{
+	"uuid": "uuid",
+	"date": "date",
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// OpenAPI : 登録されているルートパスから OpenAPI 3 形式のドキュメントを生成する
+// パスパラメータの型はアクションの引数の型、または正規表現から求め、応答の型はアクションの復帰値から求める。
+// 省略可能な部分を含むパスは、省略した場合と省略しない場合のパスへ展開する。リダイレクト、別名のルートパスは含めない。
+// メタデータが付与されている場合、ルート名を operationId、説明を summary、タグを tags とする
+func (rt *RouteTable) OpenAPI(title, version string) (*OpenAPI, error) {
+	doc := &OpenAPI{
+		OpenAPI: "3.0.3",
+		Info:    OpenAPIInfo{Title: title, Version: version},
+		Paths:   make(map[string]map[string]*OpenAPIOperation),
+	}
+	schemas := make(map[string]*OpenAPISchema)
+	named := make(map[reflect.Type]string)
+
+	// operationId が一意となるよう、パス、メソッドの順に処理する
+	type entry struct{ method, path string }
+	var entries []entry
+	for method, routes := range rt.routes {
+		if !openapiMethods[strings.ToLower(method)] {
+			continue
+		}
+		for path, route := range routes {
+			// リダイレクト、別名のルートパスは、ドキュメントへ含めない
+			if route.redirect != "" || route.alias != "" {
+				continue
+			}
+			entries = append(entries, entry{method, path})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].path != entries[j].path {
+			return entries[i].path < entries[j].path
+		}
+		return entries[i].method < entries[j].method
+	})
+
+	ids := make(map[string]int)
+	for _, e := range entries {
+		route := rt.routes[e.method][e.path]
+
+		// アクションの型情報を取得する
+		_, controller, err := rt.lookupClass(route.ctlname)
+		if err != nil {
+			return nil, err
+		}
+		caller, err := rt.Action(route.ctlname, route.actname, controller).Get()
+		if err != nil {
+			return nil, err
+		}
+		fn := caller.MethodByName(route.actname).Type()
+
+		params, variants, err := rt.scope(route.ctlname).openapiParams(e.path, fn, schemas, named)
+		if err != nil {
+			return nil, err
+		}
+		responses := openapiResponses(fn, schemas, named)
+
+		// operationId、summary は Controller.Action、tags はコントローラ名を既定値とする
+		name := route.ctlname + "." + route.actname
+		summary, tags := name, []string{route.ctlname}
+		var deprecated bool
+		if meta := route.meta; meta != nil {
+			if meta.Name != "" {
+				name = meta.Name
+			}
+			if meta.Description != "" {
+				summary = meta.Description
+			}
+			if len(meta.Tags) != 0 {
+				tags = meta.Tags
+			}
+			deprecated = meta.Deprecated
+		}
+
+		for _, variant := range variants {
+			// operationId が重複する場合は連番を付与する
+			id := name
+			if n := ids[id]; n > 0 {
+				id = fmt.Sprintf("%s_%d", id, n+1)
+			}
+			ids[name]++
+
+			op := &OpenAPIOperation{
+				Summary:     summary,
+				OperationID: id,
+				Tags:        tags,
+				Responses:   responses,
+				Deprecated:  deprecated,
+			}
+			var b strings.Builder
+			for _, tok := range variant {
+				if tok.kind == tokenLiteral {
+					b.WriteString(tok.text)
+					continue
+				}
+				b.WriteString("{" + tok.name + "}")
+				if p := params[tok.column]; p != nil && !hasParameter(op.Parameters, p.Name) {
+					op.Parameters = append(op.Parameters, p)
+				}
+			}
+			item, ok := doc.Paths[b.String()]
+			if !ok {
+				item = make(map[string]*OpenAPIOperation)
+				doc.Paths[b.String()] = item
+			}
+			item[strings.ToLower(e.method)] = op
+		}
+	}
+	if len(schemas) != 0 {
+		doc.Components = &OpenAPIComponents{Schemas: schemas}
+	}
+	return doc, nil
+}
+
+// openapiParams : パスパラメータのスキーマを求め、パラメータの位置をキーとして返却する
+// 併せて、省略可能な部分を展開したパスの一覧を返却する
+func (rt *RouteTable) openapiParams(path string, fn reflect.Type, schemas map[string]*OpenAPISchema, named map[reflect.Type]string) (map[int]*OpenAPIParameter, [][]token, error) {
+	tokens, err := parsePath(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 第1引数が context.Context の場合は、2番目以降の引数を対象とする
+	offset := 0
+	if fn.NumIn() > 0 && fn.In(0) == contextType {
+		offset = 1
+	}
+
+	params := make(map[int]*OpenAPIParameter)
+	used := make(map[string]bool)
+	group := 0
+	for i, tok := range tokens {
+		if tok.kind != tokenParam {
+			continue
+		}
+		reg, groups, _, err := rt.tokenRegexp(path, tok)
+		if err != nil {
+			return nil, nil, err
+		}
+		var schema *OpenAPISchema
+		// キャプチャグループが1つの場合は、対応するアクションの引数の型とする
+		if groups == 1 && group+offset < fn.NumIn() {
+			schema = openapiSchema(fn.In(group+offset), schemas, named)
+		}
+		if schema == nil || schema.Ref != "" {
+			schema = &OpenAPISchema{Type: "string"}
+		}
+		if schema.Type == "string" {
+			schema.Pattern = "^" + reg + "$"
+		}
+		if format, ok := constraintFormats[tok.typ]; ok {
+			schema.Format = format
+		}
+		// 同名のパラメータは OpenAPI のパスで区別できないため、2番目以降に連番を付与する (ex: /{n}/{n_2})
+		name := tok.name
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s_%d", tok.name, n)
+		}
+		used[name] = true
+		tokens[i].name = name
+		params[tok.column] = &OpenAPIParameter{Name: name, In: "path", Required: true, Schema: schema}
+		group += groups
+	}
+	return params, expandOptional(tokens), nil
+}
+
+// openapiResponses : アクションの復帰値から応答を求める。error 型の復帰値は対象外とする
+func openapiResponses(fn reflect.Type, schemas map[string]*OpenAPISchema, named map[reflect.Type]string) map[string]*OpenAPIResponse {
+	for i := 0; i < fn.NumOut(); i++ {
+		if fn.Out(i) == errorType {
+			continue
+		}
+		schema := openapiSchema(fn.Out(i), schemas, named)
+		if schema == nil {
+			continue
+		}
+		return map[string]*OpenAPIResponse{
+			"200": {
+				Description: "OK",
+				Content:     map[string]*OpenAPIMediaType{"application/json": {Schema: schema}},
+			},
+		}
+	}
+	return map[string]*OpenAPIResponse{"204": {Description: "No Content"}}
+}
+
+// openapiSchema : Go の型からスキーマを生成する。構造体は components へ登録し、参照を返却する
+// 表現できない型の場合は nil を返却する
+func openapiSchema(typ reflect.Type, schemas map[string]*OpenAPISchema, named map[reflect.Type]string) *OpenAPISchema {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == timeType {
+		return &OpenAPISchema{Type: "string", Format: "date-time"}
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return &OpenAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int64:
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &OpenAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		min := 0
+		return &OpenAPISchema{Type: "integer", Minimum: &min}
+	case reflect.Float32:
+		return &OpenAPISchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &OpenAPISchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &OpenAPISchema{Type: "string"}
+	case reflect.Interface:
+		return &OpenAPISchema{}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return &OpenAPISchema{Type: "string", Format: "byte"}
+		}
+		items := openapiSchema(typ.Elem(), schemas, named)
+		if items == nil {
+			return nil
+		}
+		return &OpenAPISchema{Type: "array", Items: items}
+	case reflect.Map:
+		if typ.Key().Kind() != reflect.String {
+			return nil
+		}
+		value := openapiSchema(typ.Elem(), schemas, named)
+		if value == nil {
+			return nil
+		}
+		return &OpenAPISchema{Type: "object", AdditionalProperties: value}
+	case reflect.Struct:
+		return openapiStruct(typ, schemas, named)
+	}
+	return nil
+}
+
+// openapiStruct : 構造体のスキーマを components へ登録し、参照を返却する
+// プロパティ名は json タグに従う
+func openapiStruct(typ reflect.Type, schemas map[string]*OpenAPISchema, named map[reflect.Type]string) *OpenAPISchema {
+	if name, ok := named[typ]; ok {
+		return &OpenAPISchema{Ref: "#/components/schemas/" + name}
+	}
+	// 名前のない構造体は、その場で展開する
+	name := typ.Name()
+	if name != "" {
+		if _, ok := schemas[name]; ok {
+			name = strings.Replace(typ.String(), ".", "_", -1)
+		}
+		named[typ] = name
+		schemas[name] = &OpenAPISchema{}
+	}
+
+	schema := &OpenAPISchema{Type: "object", Properties: make(map[string]*OpenAPISchema)}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		key, omitempty := field.Name, false
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			opts := strings.Split(tag, ",")
+			if opts[0] == "-" && len(opts) == 1 {
+				continue
+			}
+			if opts[0] != "" {
+				key = opts[0]
+			}
+			for _, opt := range opts[1:] {
+				omitempty = omitempty || opt == "omitempty"
+			}
+		}
+		prop := openapiSchema(field.Type, schemas, named)
+		if prop == nil {
+			continue
+		}
+		schema.Properties[key] = prop
+		if !omitempty && field.Type.Kind() != reflect.Ptr {
+			schema.Required = append(schema.Required, key)
+		}
+	}
+	sort.Strings(schema.Required)
+
+	if name == "" {
+		return schema
+	}
+	*schemas[name] = *schema
+	return &OpenAPISchema{Ref: "#/components/schemas/" + name}
+}
+
+// expandOptional : 省略可能な部分を、省略した場合と省略しない場合に展開した要素の一覧を返却する
+func expandOptional(tokens []token) [][]token {
+	var results = [][]token{{}}
+	for i := 0; i < len(tokens); i++ {
+		if tokens[i].kind != tokenOpen {
+			for j := range results {
+				results[j] = append(results[j], tokens[i])
+			}
+			continue
+		}
+		// 対応する ')' までを展開する
+		end, depth := i, 0
+		for ; end < len(tokens); end++ {
+			if tokens[end].kind == tokenOpen {
+				depth++
+			} else if tokens[end].kind == tokenClose {
+				depth--
+			}
+			if depth == 0 {
+				break
+			}
+		}
+		inner := expandOptional(tokens[i+1 : end])
+		var next [][]token
+		for _, r := range results {
+			next = append(next, r)
+			for _, in := range inner {
+				next = append(next, append(append([]token{}, r...), in...))
+			}
+		}
+		results = next
+		i = end
+	}
+	return results
+}
+
+// hasParameter : 同じ名前のパラメータが含まれるか判定する
+func hasParameter(params []*OpenAPIParameter, name string) bool {
+	for _, p := range params {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type User struct {
+	ID      int       `json:"id"`
+	Name    string    `json:"name"`
+	Email   string    `json:"email,omitempty"`
+	Friends []*User   `json:"friends"`
+	Created time.Time `json:"created"`
+	secret  string
+}
+
+type Users struct{}
+
+func (c *Users) Index() ([]User, error)                          { return nil, nil }
+func (c *Users) Show(ctx context.Context, id int) (*User, error) { return nil, nil }
+func (c *Users) Find(name string) User                           { return User{} }
+func (c *Users) Delete(id string) error                          { return nil }
+func (c *Users) Archive(year int, day time.Time) []string        { return nil }
+
+func Test__OPENAPI(t *testing.T) {
+	r := New()
+	r.AddClass(Users{})
+	r.AddRegexp("name", "([a-z]+)")
+	r.Register("GET", "/users", "Users.Index")
+	r.Register("GET", "/users/:id<int>", "Users.Show")
+	r.Register("GET", "/users/find/:name", "Users.Find")
+	r.Register("DELETE", "/users/{id:[0-9]+}", "Users.Delete")
+	r.Register("GET", "/archive/:year<int>(/:day<date>)", "Users.Archive")
+	r.Register("CONNECT", "/users", "Users.Index")
+	doc, err := r.OpenAPI("Users API", "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := json.Marshal(doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(doc.Paths) != 5 || doc.Paths["/users"]["get"] == nil || len(doc.Paths["/users"]) != 1 {
+		t.Fatal("OpenAPI: Error", doc.Paths)
+	}
+
+	// アクションの引数の型、または正規表現からパラメータの型を求める
+	var tests = []struct {
+		path, method, name, typ, format, pattern string
+	}{
+		{"/users/{id}", "get", "id", "integer", "int64", ""},
+		{"/users/{id}", "delete", "id", "string", "", "^([0-9]+)$"},
+		{"/users/find/{name}", "get", "name", "string", "", "^([a-z]+)$"},
+		{"/archive/{year}/{day}", "get", "day", "string", "date", "^([0-9]{4}-[0-9]{2}-[0-9]{2})$"},
+	}
+	for _, test := range tests {
+		op := doc.Paths[test.path][test.method]
+		if op == nil {
+			t.Fatal("OpenAPI: Error", test.path, test.method)
+		}
+		var param *OpenAPIParameter
+		for _, p := range op.Parameters {
+			if p.Name == test.name {
+				param = p
+			}
+		}
+		if param == nil || !param.Required || param.In != "path" {
+			t.Fatal("OpenAPI: Error", test.path, param)
+		}
+		if s := param.Schema; s.Type != test.typ || s.Format != test.format || s.Pattern != test.pattern {
+			t.Fatal("OpenAPI: Error", test.path, s)
+		}
+	}
+
+	// 省略可能な部分は展開される
+	if op := doc.Paths["/archive/{year}"]["get"]; op == nil || len(op.Parameters) != 1 || op.OperationID != "Users.Archive" {
+		t.Fatal("OpenAPI: Error", op)
+	}
+	if op := doc.Paths["/archive/{year}/{day}"]["get"]; op.OperationID != "Users.Archive_2" || op.Tags[0] != "Users" {
+		t.Fatal("OpenAPI: Error", op)
+	}
+
+	// 復帰値から応答の型を求める
+	if res := doc.Paths["/users/{id}"]["delete"].Responses["204"]; res == nil {
+		t.Fatal("OpenAPI: Error", doc.Paths["/users/{id}"]["delete"].Responses)
+	}
+	if s := doc.Paths["/users"]["get"].Responses["200"].Content["application/json"].Schema; s.Type != "array" || s.Items.Ref != "#/components/schemas/User" {
+		t.Fatal("OpenAPI: Error", s)
+	}
+	user := doc.Components.Schemas["User"]
+	if user == nil || len(user.Properties) != 5 || user.Properties["friends"].Items.Ref != "#/components/schemas/User" {
+		t.Fatal("OpenAPI: Error", user)
+	}
+	if len(user.Required) != 4 || user.Properties["created"].Format != "date-time" {
+		t.Fatal("OpenAPI: Error", user.Required)
+	}
+
+	// 同名のパラメータには連番を付与する
+	r.Register("GET", "/pair/:name/:name", "Users.Archive")
+	doc, err = r.OpenAPI("Users API", "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op := doc.Paths["/pair/{name}/{name_2}"]["get"]; op == nil || len(op.Parameters) != 2 || op.Parameters[0].Name != "name" || op.Parameters[1].Name != "name_2" {
+		t.Fatal("OpenAPI: Error", doc.Paths)
+	}
+
+	// コントローラが未登録の場合はエラーとなる
+	r.Register("GET", "/posts", "Posts.Index")
+	if _, err := r.OpenAPI("Users API", "1.0.0"); err == nil {
+		t.Fatal("OpenAPI: Error")
+	}
+}
@@ -0,0 +1,140 @@
+package router
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// TrailingSlash : 末尾のスラッシュの取り扱い方法
+type TrailingSlash int
+
+const (
+	// TrailingSlashStrict : 末尾のスラッシュの有無を区別する
+	TrailingSlashStrict TrailingSlash = iota
+	// TrailingSlashIgnore : 末尾のスラッシュの有無を区別せず、どちらでもマッチさせる
+	TrailingSlashIgnore
+	// TrailingSlashRedirect : 末尾のスラッシュの有無のみが異なる場合、NotRoutes.Redirect にリダイレクト先を設定する
+	TrailingSlashRedirect
+)
+
+// Options : Router.Caller でのパスの照合方法を指定する構造体
+type Options struct {
+	CaseInsensitive bool          // 大文字、小文字を区別せずに照合する
+	TrailingSlash   TrailingSlash // 末尾のスラッシュの取り扱い方法
+	CollapseSlashes bool          // 連続したスラッシュを1つにまとめて照合する (ex: /users//1 -> /users/1)
+	CleanPath       bool          // "." と ".." を解決して照合する (ex: /users/./1/../2 -> /users/2)。連続したスラッシュもまとめる
+	RawCaptures     bool          // 正規表現で抜き出した値を、パーセントエンコードされたまま引数とする
+	Suggest         bool          // 該当するアクションが存在しない場合、NotRoutes.Suggestions へ候補となるルートを設定する
+}
+
+// normalize : オプションに従い、照合するパスを正規化する
+func (opts *Options) normalize(p string) string {
+	if opts.CollapseSlashes {
+		for strings.Contains(p, "//") {
+			p = strings.Replace(p, "//", "/", -1)
+		}
+	}
+	if opts.CleanPath && strings.HasPrefix(p, "/") {
+		// path.Clean は末尾のスラッシュを取り除くため、元のパスに合わせて付与し直す
+		cleaned := path.Clean(p)
+		if strings.HasSuffix(p, "/") && cleaned != "/" {
+			cleaned += "/"
+		}
+		p = cleaned
+	}
+	return p
+}
+
+// key : 固定パスの登録、検索に使用するキー名を返却する
+// 登録時も照合時と同様に正規化するため、/a//b で登録した固定パスは CollapseSlashes の場合 /a/b として扱う
+func (opts *Options) key(p string) string {
+	p = opts.normalize(p)
+	if opts.CaseInsensitive {
+		return strings.ToLower(p)
+	}
+	return p
+}
+
+// normalized : 正規表現形式のパスが、正規化後のパスと一致するか検証する
+// 照合するパスは正規化されるため、一致しない場合はどのパスにも一致しないルートパスとなる
+func (opts *Options) normalized(method, p string) error {
+	if n := opts.normalize(p); n != p {
+		return &InvalidPath{
+			Message: fmt.Sprintf("'[%s]: %s' - path is not normalized. use '%s'", method, p, n),
+			Method:  method,
+			Path:    p,
+		}
+	}
+	return nil
+}
+
+// toggleSlash : 末尾のスラッシュの有無を反転したパスを返却する。反転できない場合は空文字列を返却する
+func toggleSlash(p string) string {
+	if p == "" || p == "/" {
+		return ""
+	}
+	if strings.HasSuffix(p, "/") {
+		return p[:len(p)-1]
+	}
+	return p + "/"
+}
+
+// unescapePath : パーセントエンコードされたパスを、セグメントの区切りを維持したままデコードする
+// "/" と "%" はパスの構造に影響するため、%2F、%25 のままとする (ex: /a%20b%2Fc -> /a b%2Fc)
+func unescapePath(p string) (string, error) {
+	decoded, _, err := unescapeOffsets(p, false)
+	return decoded, err
+}
+
+// unescapeOffsets : unescapePath と同様にデコードする。offsets が true の場合は、デコード後の各位置に対応する
+// p の位置を返却する。デコード後のパスで照合し、エンコードされたままの値を p から抜き出す場合に使用する
+func unescapeOffsets(p string, offsets bool) (string, []int, error) {
+	if !strings.Contains(p, "%") && !offsets {
+		return p, nil, nil
+	}
+	var (
+		b   strings.Builder
+		pos []int
+	)
+	for i := 0; i < len(p); i++ {
+		if offsets {
+			pos = append(pos, i)
+		}
+		if p[i] != '%' {
+			b.WriteByte(p[i])
+			continue
+		}
+		if i+2 >= len(p) {
+			return "", nil, fmt.Errorf("'%s' - invalid URL escape", p)
+		}
+		c, err := strconv.ParseUint(p[i+1:i+3], 16, 8)
+		if err != nil {
+			return "", nil, fmt.Errorf("'%s' - invalid URL escape '%s'", p, p[i:i+3])
+		}
+		if c == '/' || c == '%' {
+			b.WriteString(strings.ToUpper(p[i : i+3]))
+			// %2F、%25 はデコードしないため、各文字をエンコードされたパスの位置へ対応させる
+			if offsets {
+				pos = append(pos, i+1, i+2)
+			}
+		} else {
+			b.WriteByte(byte(c))
+		}
+		i += 2
+	}
+	if offsets {
+		pos = append(pos, len(p))
+	}
+	return b.String(), pos, nil
+}
+
+// capture : 正規表現で抜き出した値を、オプションに従いデコードする
+func (opts *Options) capture(v string) (string, error) {
+	if opts.RawCaptures {
+		return v, nil
+	}
+	return url.PathUnescape(v)
+}
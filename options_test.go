@@ -0,0 +1,238 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func optionsRouter(t *testing.T, opts Options) Router {
+	r := New()
+	r.Options = opts
+	r.AddClass(Sample{})
+	r.AddRegexp("n", "([0-9A-Za-z]+)")
+	r.Register("GET", "/Users", "Sample.Index")
+	r.Register("GET", "/users/new/", "Sample.World")
+	r.Register("GET", "/Users/:n/:n", "Sample.Hello")
+	router, err := r.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return router
+}
+
+func Test__OPTIONS_STRICT(t *testing.T) {
+	router := optionsRouter(t, Options{})
+	for _, path := range []string{"/users", "/Users/", "/users/new", "//Users", "/Users/./1/2"} {
+		if _, _, err := router.Caller("GET", path); err == nil {
+			t.Fatal("Caller: Error", path)
+		}
+	}
+	if _, _, err := router.Caller("GET", "/Users"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test__OPTIONS_CASEINSENSITIVE(t *testing.T) {
+	router := optionsRouter(t, Options{CaseInsensitive: true})
+	if caller, _, err := router.Caller("GET", "/USERS"); err != nil {
+		t.Fatal(err)
+	} else if _, actname := caller.Name(); actname != "Index" {
+		t.Fatal("Caller: Error", actname)
+	}
+	// 抜き出した引数は、大文字、小文字を維持する
+	caller, args, err := router.Caller("GET", "/users/Ab/Cd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result, _ := caller.Call(args); result[0].String() != "Hello Ab Cd" {
+		t.Fatal("Caller: Error", result[0].String())
+	}
+
+	// 大文字、小文字のみが異なる固定パスを登録した場合はエラーとなる
+	r := New()
+	r.Options.CaseInsensitive = true
+	r.AddClass(Sample{})
+	r.Register("GET", "/Users", "Sample.Index")
+	r.Register("GET", "/users", "Sample.World")
+	if _, err := r.Create(); err == nil {
+		t.Fatal("Create: Error")
+	}
+}
+
+func Test__OPTIONS_TRAILINGSLASH(t *testing.T) {
+	router := optionsRouter(t, Options{TrailingSlash: TrailingSlashIgnore})
+	for path, actname := range map[string]string{
+		"/Users/":     "Index",
+		"/users/new":  "World",
+		"/Users/1/2/": "Hello",
+	} {
+		caller, _, err := router.Caller("GET", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, name := caller.Name(); name != actname {
+			t.Fatal("Caller: Error", path, name)
+		}
+	}
+
+	router = optionsRouter(t, Options{TrailingSlash: TrailingSlashRedirect})
+	_, _, err := router.Caller("GET", "/users/new")
+	if v, ok := err.(*NotRoutes); !ok || v.Redirect != "/users/new/" {
+		t.Fatal("Caller: Error", err)
+	}
+	_, _, err = router.Caller("GET", "/Users/1/2/")
+	if v, ok := err.(*NotRoutes); !ok || v.Redirect != "/Users/1/2" {
+		t.Fatal("Caller: Error", err)
+	}
+	_, _, err = router.Caller("GET", "/")
+	if v, ok := err.(*NotRoutes); !ok || v.Redirect != "" {
+		t.Fatal("Caller: Error", err)
+	}
+}
+
+func Test__OPTIONS_CLEAN(t *testing.T) {
+	router := optionsRouter(t, Options{CollapseSlashes: true})
+	if _, _, err := router.Caller("GET", "//Users///1//2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := router.Caller("GET", "/Users/./1/2"); err == nil {
+		t.Fatal("Caller: Error")
+	}
+
+	router = optionsRouter(t, Options{CleanPath: true})
+	for _, path := range []string{"/Users/./1/2", "/Users/3/../1/2", "//Users//1/2", "/users/../users/new/"} {
+		if _, _, err := router.Caller("GET", path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 末尾のスラッシュは維持される
+	if _, _, err := router.Caller("GET", "/Users/./"); err == nil {
+		t.Fatal("Caller: Error")
+	}
+}
+
+func Test__OPTIONS_CLEAN_REGISTER(t *testing.T) {
+	// 固定パスは、登録時も照合時と同様に正規化する
+	for _, opts := range []Options{{CollapseSlashes: true}, {CleanPath: true}} {
+		r := New()
+		r.Options = opts
+		r.AddClass(Sample{})
+		r.Register("GET", "/a//b", "Sample.Index")
+		r.Register("GET", "/c/./d/", "Sample.World")
+		router, err := r.Create()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range []string{"/a/b", "/a//b"} {
+			if _, _, err := router.Caller("GET", path); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, _, err := router.Caller("GET", "/c/d/"); opts.CleanPath && err != nil {
+			t.Fatal(err)
+		}
+
+		// 正規化後のパスが同じ固定パスは重複となる
+		r.Register("GET", "/a/b", "Sample.World")
+		if _, err := r.Create(); err == nil {
+			t.Fatal("Create: Error", opts)
+		}
+		if errs := r.Lint(); len(errs) != 1 {
+			t.Fatal("Lint: Error", opts, errs)
+		}
+	}
+
+	// 正規化後のパスと一致しない正規表現形式のパスは、照合できないためエラーとなる
+	r := New()
+	r.Options.CollapseSlashes = true
+	r.AddClass(Sample{})
+	r.AddRegexp("n", "([0-9]+)")
+	r.Register("GET", "/users//:n", "Sample.TheTest")
+	var invalid *InvalidPath
+	if _, err := r.Create(); !errors.As(err, &invalid) || invalid.Path != "/users//:n" {
+		t.Fatal("Create: Error", err)
+	}
+	if errs := r.Lint(); len(errs) != 1 || !errors.Is(errs[0], ErrInvalidPath) {
+		t.Fatal("Lint: Error", errs)
+	}
+	r.Options.CollapseSlashes = false
+	if _, err := r.Create(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test__OPTIONS_ESCAPE(t *testing.T) {
+	newRouter := func(opts Options) Router {
+		r := New()
+		r.Options = opts
+		r.AddClass(Sample{})
+		r.AddRegexp("name", "([^/]+)")
+		r.Register("GET", "/files/a/b", "Sample.Index")
+		r.Register("GET", "/files/a b", "Sample.World")
+		r.Register("GET", "/files/:name", "Sample.TheTest")
+		r.Register("GET", "/files/:name/:name", "Sample.Hello")
+		router, err := r.Create()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return router
+	}
+
+	router := newRouter(Options{})
+	var tests = []struct {
+		path, actname, args string
+	}{
+		// %2F はセグメントの区切りとして扱わない
+		{"/files/a/b", "Index", "[]"},
+		{"/files/a%2Fb", "TheTest", "[a/b]"},
+		{"/files/a%2fb", "TheTest", "[a/b]"},
+		// %2F 以外は、デコードして照合する
+		{"/files/a%20b", "World", "[]"},
+		{"/files/a%2Fb/c%20d", "Hello", "[a/b c d]"},
+		{"/files/100%25", "TheTest", "[100%]"},
+		{"/files/%252F", "TheTest", "[%2F]"},
+	}
+	for _, test := range tests {
+		caller, args, err := router.Caller("GET", test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, actname := caller.Name(); actname != test.actname || fmt.Sprint(args) != test.args {
+			t.Fatal("Caller: Error", test.path, actname, args)
+		}
+	}
+	// 不正なエスケープの場合はマッチしない
+	for _, path := range []string{"/files/100%", "/files/%zz", "/files/%2"} {
+		if _, _, err := router.Caller("GET", path); err == nil {
+			t.Fatal("Caller: Error", path)
+		}
+	}
+
+	// RawCaptures の場合、抜き出した値はエンコードされたままとなる
+	router = newRouter(Options{RawCaptures: true})
+	_, args, err := router.Caller("GET", "/files/a%2Fb/c%20d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(args) != "[a%2Fb c%20d]" {
+		t.Fatal("Caller: Error", args)
+	}
+	// 照合はデコードしたパスで行うため、エンコードされた文字を含む固定部分も一致する
+	r := New()
+	r.Options.RawCaptures = true
+	r.AddClass(Sample{})
+	r.AddRegexp("name", "([^/]+)")
+	r.Register("GET", "/ファイル/a b/:name", "Sample.TheTest")
+	router, err = r.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, args, err = router.Caller("GET", "/%E3%83%95%E3%82%A1%E3%82%A4%E3%83%AB/a%20b/%E6%97%A5%2F%25")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(args) != "[%E6%97%A5%2F%25]" {
+		t.Fatal("Caller: Error", args)
+	}
+}
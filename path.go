@@ -0,0 +1,660 @@
+package router
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ParseError : パスの構文が不正な場合のエラー型
+type ParseError struct {
+	Message string
+	Method  string
+	Path    string
+	Column  int   // 不正な箇所の位置 (1 始まり、文字単位)
+	Err     error // 原因となったエラー。未登録の正規表現の場合は *RegexpNotRegistered が格納される
+}
+
+func (err *ParseError) Error() string {
+	return err.Message
+}
+
+// Unwrap : 原因となったエラーを返却する
+func (err *ParseError) Unwrap() error {
+	return err.Err
+}
+
+// Is : ErrInvalidPath と比較した場合 true を返却する
+func (err *ParseError) Is(target error) bool {
+	return target == ErrInvalidPath
+}
+
+func (err *ParseError) setRoute(method, path string) {
+	err.Method, err.Path = method, path
+}
+
+// tokenKind : パスを構成する要素の種類
+type tokenKind int
+
+const (
+	tokenLiteral tokenKind = iota // 固定文字列
+	tokenParam                    // パラメータ
+	tokenOpen                     // 省略可能な部分の開始 '('
+	tokenClose                    // 省略可能な部分の終了 ')'
+)
+
+// token : パスを構成する要素
+type token struct {
+	kind   tokenKind
+	text   string // 固定文字列
+	name   string // パラメータ名
+	typ    string // 型制約 (:name<type>)
+	regexp string // インライン正規表現 ({name:regexp})
+	brace  bool   // {name} 形式で指定された場合 true
+	column int    // パス内の位置 (1 始まり、文字単位)
+}
+
+// reserved : パス内で特別な意味を持つ文字
+const reserved = ":{}()\\"
+
+// legacyMeta : 以前のバージョンで、':' を含むパスの場合に正規表現の構文として解釈されていた文字
+const legacyMeta = "[]|*+?^$"
+
+// isNameChar : パラメータ名に使用可能な文字か判定する
+func isNameChar(c byte) bool {
+	return c == '_' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// parser : パスを要素へ分解する構造体
+type parser struct {
+	path   string
+	pos    int
+	tokens []token
+	text   strings.Builder // 読み込み中の固定文字列
+	start  int             // 読み込み中の固定文字列の開始位置
+	opens  []int           // 閉じられていない '(' の位置
+	named  bool            // :name 形式のパラメータを含む場合 true
+	meta   int             // 固定文字列内の、最初のエスケープされていない legacyMeta の文字の位置。含まない場合は -1
+}
+
+// parsePath : パスを固定文字列とパラメータへ分解する
+// パラメータは次の形式で指定可能
+//
+//	:name          AddRegexp で登録した正規表現
+//	:name<type>    組み込みの型制約
+//	{name}         AddRegexp で登録した正規表現。未登録の場合は [^/]+。キャプチャグループ数によらず引数は1つとなる
+//	{name:regexp}  インライン正規表現
+//
+// '(' と ')' で囲んだ部分は省略可能となる (ex: /archive(/:year(/:month)))。
+// 記号の前に '\' を付与した場合は、記号を固定文字列として扱う (ex: /files\(1\))
+func parsePath(path string) ([]token, error) {
+	p, err := parse(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.tokens, nil
+}
+
+// parse : パスを要素へ分解し、分解に使用した parser を返却する
+func parse(path string) (*parser, error) {
+	p := &parser{path: path, meta: -1}
+	for p.pos < len(p.path) {
+		c := p.path[p.pos]
+		switch c {
+		case '\\':
+			// 記号のエスケープ。記号以外が続く場合は '\' を固定文字列として扱う
+			if p.text.Len() == 0 {
+				p.start = p.pos
+			}
+			if p.pos+1 < len(p.path) && isEscapable(p.path[p.pos+1]) {
+				p.text.WriteByte(p.path[p.pos+1])
+				p.pos += 2
+				continue
+			}
+			p.text.WriteByte(c)
+			p.pos++
+		case ':':
+			if err := p.colon(); err != nil {
+				return nil, err
+			}
+		case '{':
+			if err := p.brace(); err != nil {
+				return nil, err
+			}
+		case '(':
+			p.flush()
+			p.opens = append(p.opens, p.pos)
+			p.tokens = append(p.tokens, token{kind: tokenOpen, column: p.column(p.pos)})
+			p.pos++
+		case ')':
+			p.flush()
+			if len(p.opens) == 0 {
+				return nil, p.errorf(p.pos, "unexpected ')'")
+			}
+			if p.tokens[len(p.tokens)-1].kind == tokenOpen {
+				return nil, p.errorf(p.opens[len(p.opens)-1], "empty optional segment")
+			}
+			p.opens = p.opens[:len(p.opens)-1]
+			p.tokens = append(p.tokens, token{kind: tokenClose, column: p.column(p.pos)})
+			p.pos++
+		case '}':
+			return nil, p.errorf(p.pos, "unexpected '%c'", c)
+		default:
+			// 正規表現は不正な UTF-8 のバイト列に一致させられないため、エラーとする
+			r, size := utf8.DecodeRuneInString(p.path[p.pos:])
+			if r == utf8.RuneError && size == 1 {
+				return nil, p.errorf(p.pos, "invalid UTF-8")
+			}
+			if p.text.Len() == 0 {
+				p.start = p.pos
+			}
+			if p.meta == -1 && strings.IndexByte(legacyMeta, c) != -1 {
+				p.meta = p.pos
+			}
+			p.text.WriteString(p.path[p.pos : p.pos+size])
+			p.pos += size
+		}
+	}
+	p.flush()
+	if len(p.opens) != 0 {
+		return nil, p.errorf(p.opens[len(p.opens)-1], "unclosed '('")
+	}
+	return p, nil
+}
+
+// isEscapable : '\' でエスケープ可能な記号か判定する
+func isEscapable(c byte) bool {
+	return c < utf8.RuneSelf && c > ' ' && c != 0x7f && !isNameChar(c)
+}
+
+// legacyRegexp : :name 形式のパラメータを含むパスの固定文字列に、正規表現の構文が含まれていないか検証する
+// 以前のバージョンでは ':' を含むパス全体を正規表現として扱っていたため、このようなパスは意味が変わる。
+// 構文が不正なパスは Create でエラーとするため、ここでは検証しない
+func legacyRegexp(path string) error {
+	p, err := parse(path)
+	if err != nil || !p.named || p.meta == -1 {
+		return nil
+	}
+	return p.errorf(p.meta, "regexp syntax '%c' is not allowed in path. use {name:regexp}, or escape it with '\\'", p.path[p.meta])
+}
+
+// column : バイト位置を文字単位の位置(1 始まり)へ変換する
+func (p *parser) column(pos int) int {
+	return utf8.RuneCountInString(p.path[:pos]) + 1
+}
+
+func (p *parser) errorf(pos int, format string, args ...interface{}) *ParseError {
+	col := p.column(pos)
+	return &ParseError{
+		Message: fmt.Sprintf("'%s' - %s at column %d", p.path, fmt.Sprintf(format, args...), col),
+		Path:    p.path,
+		Column:  col,
+	}
+}
+
+// flush : 読み込み中の固定文字列を要素として追加する
+func (p *parser) flush() {
+	if p.text.Len() == 0 {
+		return
+	}
+	p.tokens = append(p.tokens, token{kind: tokenLiteral, text: p.text.String(), column: p.column(p.start)})
+	p.text.Reset()
+}
+
+// name : pos から始まるパラメータ名を読み込む
+func (p *parser) name() string {
+	start := p.pos
+	for p.pos < len(p.path) && isNameChar(p.path[p.pos]) {
+		p.pos++
+	}
+	return p.path[start:p.pos]
+}
+
+// colon : :name、:name<type> 形式のパラメータを読み込む
+func (p *parser) colon() error {
+	p.flush()
+	start := p.pos
+	p.pos++
+	tok := token{kind: tokenParam, name: p.name(), column: p.column(start)}
+	if tok.name == "" {
+		return p.errorf(start, "missing parameter name after ':'")
+	}
+	p.named = true
+	// 型制約を読み込む
+	if p.pos < len(p.path) && p.path[p.pos] == '<' {
+		open := p.pos
+		end := strings.IndexByte(p.path[open:], '>')
+		if end == -1 {
+			return p.errorf(open, "unclosed '<'")
+		}
+		tok.typ = p.path[open+1 : open+end]
+		if tok.typ == "" {
+			return p.errorf(open, "missing constraint name")
+		}
+		p.pos = open + end + 1
+	}
+	p.tokens = append(p.tokens, tok)
+	return nil
+}
+
+// brace : {name}、{name:regexp} 形式のパラメータを読み込む
+func (p *parser) brace() error {
+	p.flush()
+	start := p.pos
+	p.pos++
+	tok := token{kind: tokenParam, name: p.name(), brace: true, column: p.column(start)}
+	if tok.name == "" {
+		if p.pos < len(p.path) && p.path[p.pos] != '}' && p.path[p.pos] != ':' {
+			return p.errorf(p.pos, "invalid character '%c' in parameter name", p.path[p.pos])
+		}
+		return p.errorf(start, "missing parameter name after '{'")
+	}
+	if p.pos >= len(p.path) {
+		return p.errorf(start, "unclosed '{'")
+	}
+	switch p.path[p.pos] {
+	case '}':
+		p.pos++
+	case ':':
+		// 対応する '}' までをインライン正規表現として読み込む。正規表現内の {n,m} を考慮する
+		p.pos++
+		begin, depth := p.pos, 0
+		for ; p.pos < len(p.path); p.pos++ {
+			switch p.path[p.pos] {
+			case '\\':
+				p.pos++
+				continue
+			case '{':
+				depth++
+				continue
+			case '}':
+				depth--
+			default:
+				continue
+			}
+			if depth < 0 {
+				break
+			}
+		}
+		if p.pos >= len(p.path) {
+			return p.errorf(start, "unclosed '{'")
+		}
+		tok.regexp = p.path[begin:p.pos]
+		if tok.regexp == "" {
+			return p.errorf(begin, "missing regexp")
+		}
+		// インライン正規表現を検証する
+		re, err := syntax.Parse(tok.regexp, syntax.Perl)
+		if err != nil {
+			e := p.errorf(begin, "invalid regexp. %s", err)
+			e.Err = &InvalidRegexp{Message: err.Error(), Regexp: tok.regexp, Err: err}
+			return e
+		}
+		if re.MaxCap() != 0 {
+			return p.errorf(begin, "capturing group in regexp. use (?:...) instead")
+		}
+		p.pos++
+	default:
+		return p.errorf(p.pos, "invalid character '%c' in parameter name", p.path[p.pos])
+	}
+	p.tokens = append(p.tokens, tok)
+	return nil
+}
+
+// compiled : 正規表現文字列へ変換したパス
+type compiled struct {
+	pattern    string      // 正規表現文字列
+	converters []converter // キャプチャグループ順の変換関数。nil の要素は文字列のまま渡す
+	types      []string    // キャプチャグループ順の型制約名。型制約が無い場合は空文字列
+	names      []string    // キャプチャグループ順のパラメータ名
+	optional   []bool      // キャプチャグループが省略可能な部分に含まれる場合 true
+}
+
+// compile : パスを正規表現文字列へ変換し、キャプチャグループ順の変換関数と併せて返却する
+func (rt *RouteTable) compile(path string) (*compiled, error) {
+	tokens, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		b     strings.Builder
+		c     = &compiled{}
+		depth int
+	)
+	// キャプチャグループの情報を追加する
+	group := func(tok token, conv converter) {
+		c.converters = append(c.converters, conv)
+		c.types = append(c.types, tok.typ)
+		c.names = append(c.names, tok.name)
+		c.optional = append(c.optional, depth > 0)
+	}
+	for _, tok := range tokens {
+		switch {
+		case tok.kind == tokenLiteral:
+			// 固定文字列は、正規表現として解釈しない
+			b.WriteString(regexp.QuoteMeta(tok.text))
+		case tok.kind == tokenOpen:
+			b.WriteString("(?:")
+			depth++
+		case tok.kind == tokenClose:
+			b.WriteString(")?")
+			depth--
+		default:
+			reg, groups, conv, err := rt.tokenRegexp(path, tok)
+			if err != nil {
+				return nil, err
+			}
+			b.WriteString(reg)
+			for i := 0; i < groups; i++ {
+				group(tok, conv)
+			}
+		}
+	}
+	c.pattern = b.String()
+	return c, nil
+}
+
+// tokenRegexp : パラメータを正規表現文字列へ変換し、キャプチャグループ数、変換関数と併せて返却する
+func (rt *RouteTable) tokenRegexp(path string, tok token) (string, int, converter, error) {
+	switch {
+	case tok.typ != "":
+		// 組み込みの型制約
+		con, ok := constraints[tok.typ]
+		if !ok {
+			return "", 0, nil, tokenError(path, tok, "unknown constraint '%s'", tok.typ)
+		}
+		return "(" + con.regexp + ")", 1, con.convert, nil
+	case tok.regexp != "":
+		// インライン正規表現
+		return "(" + tok.regexp + ")", 1, nil, nil
+	}
+	// 登録済みの正規表現。{name} 形式で未登録の場合は、1セグメントに一致させる
+	reg, ok := rt.regex[":"+tok.name]
+	if !ok {
+		if !tok.brace {
+			e := tokenError(path, tok, "regexp ':%s' is not registered", tok.name)
+			e.Err = &RegexpNotRegistered{
+				Message: fmt.Sprintf("'%s' - regexp ':%s' is not registered", path, tok.name),
+				Path:    path,
+				Name:    tok.name,
+			}
+			return "", 0, nil, e
+		}
+		reg = "([^/]+)"
+	}
+	re, err := syntax.Parse(reg, syntax.Perl)
+	if err != nil {
+		e := tokenError(path, tok, "invalid regexp ':%s'. %s", tok.name, err)
+		e.Err = &InvalidRegexp{Message: err.Error(), Name: tok.name, Regexp: reg, Err: err}
+		return "", 0, nil, e
+	}
+	// {name} 形式の場合は、正規表現のキャプチャグループ数によらず1つの引数とする
+	if tok.brace && re.MaxCap() != 1 {
+		return "(" + uncapture(re).String() + ")", 1, nil, nil
+	}
+	return reg, re.MaxCap(), nil, nil
+}
+
+// uncapture : 正規表現のキャプチャグループを、キャプチャしないグループへ変換する
+func uncapture(re *syntax.Regexp) *syntax.Regexp {
+	for i, sub := range re.Sub {
+		re.Sub[i] = uncapture(sub)
+	}
+	if re.Op == syntax.OpCapture {
+		return re.Sub[0]
+	}
+	return re
+}
+
+// BuildPath : パスのパラメータへ値を埋め込み、パーセントエンコードしたパスを返却する
+// 省略可能な部分は、含まれるパラメータの値がすべて指定されている場合のみ出力する。
+// 値がパラメータの正規表現、型制約を満たさない場合はエラーを返却する
+// ex) r.BuildPath("/users/:id<int>(/:tab)", map[string]interface{}{"id": 1}) // /users/1
+func (rt *RouteTable) BuildPath(path string, params map[string]interface{}) (string, error) {
+	tokens, err := parsePath(path)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if _, _, err := rt.build(&b, path, tokens, params, false); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// build : 要素へ値を埋め込み、b へ出力する。パラメータの値を埋め込んだ場合は used を true とする
+// optional が true の場合、値が指定されていないパラメータがあれば出力せずに ok を false とする
+func (rt *RouteTable) build(b *strings.Builder, path string, tokens []token, params map[string]interface{}, optional bool) (used, ok bool, err error) {
+	var out strings.Builder
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch tok.kind {
+		case tokenLiteral:
+			out.WriteString((&url.URL{Path: tok.text}).EscapedPath())
+		case tokenOpen:
+			// 対応する ')' までを省略可能な部分として出力する
+			end, depth := i, 0
+			for ; end < len(tokens); end++ {
+				if tokens[end].kind == tokenOpen {
+					depth++
+				} else if tokens[end].kind == tokenClose {
+					depth--
+				}
+				if depth == 0 {
+					break
+				}
+			}
+			var inner strings.Builder
+			innerUsed, innerOk, err := rt.build(&inner, path, tokens[i+1:end], params, true)
+			if err != nil {
+				return false, false, err
+			}
+			if innerOk && innerUsed {
+				out.WriteString(inner.String())
+				used = true
+			}
+			i = end
+		case tokenParam:
+			v, exists := params[tok.name]
+			if !exists || v == nil {
+				if optional {
+					return false, false, nil
+				}
+				return false, false, tokenError(path, tok, "parameter '%s' is required", tok.name)
+			}
+			value, err := rt.buildParam(path, tok, v)
+			if err != nil {
+				return false, false, err
+			}
+			out.WriteString(value)
+			used = true
+		}
+	}
+	b.WriteString(out.String())
+	return used, true, nil
+}
+
+// buildParam : パラメータの値を検証し、パーセントエンコードした文字列を返却する
+func (rt *RouteTable) buildParam(path string, tok token, v interface{}) (string, error) {
+	var value string
+	switch v := v.(type) {
+	case string:
+		value = v
+	case time.Time:
+		value = v.Format("2006-01-02")
+	default:
+		value = fmt.Sprint(v)
+	}
+	escaped := url.PathEscape(value)
+
+	// Caller で照合する形式の値が、パラメータの正規表現に一致するか検証する
+	reg, _, conv, err := rt.tokenRegexp(path, tok)
+	if err != nil {
+		return "", err
+	}
+	// Caller はデコードしたパスで照合するため、"%" と "/" のみエンコードした値で検証する
+	target := strings.NewReplacer("%", "%25", "/", "%2F").Replace(value)
+	p := "^(?:" + reg + ")$"
+	if rt.Options.CaseInsensitive {
+		p = "(?i)" + p
+	}
+	re, err := regexp.Compile(p)
+	if err != nil {
+		return "", tokenError(path, tok, "invalid regexp ':%s'. %s", tok.name, err)
+	}
+	if !re.MatchString(target) {
+		return "", tokenError(path, tok, "invalid value '%s' for '%s'", value, tok.name)
+	}
+	if conv != nil {
+		captured := value
+		if rt.Options.RawCaptures {
+			captured = escaped
+		}
+		if _, err := conv(captured); err != nil {
+			return "", tokenError(path, tok, "invalid value '%s' for '%s'. %s", value, tok.name, err)
+		}
+	}
+	return escaped, nil
+}
+
+// pattern : 正規表現形式のパスに対応するアクションと、抜き出した値の変換関数
+type pattern struct {
+	action     Result
+	converters []converter     // キャプチャグループ順の変換関数。nil の要素は文字列のまま渡す
+	defaults   []reflect.Value // 省略された場合に渡す値。無効な値の要素は空文字列を渡す
+}
+
+// newPattern : 省略可能なパラメータの既定値を求め、pattern を生成する
+// 既定値が指定されていない場合は、アクションの引数の型 fn のゼロ値を既定値とする
+func newPattern(path string, c *compiled, action Result, fn reflect.Type, defaults map[string]interface{}) (*pattern, error) {
+	p := &pattern{action: action, converters: append([]converter(nil), c.converters...), defaults: make([]reflect.Value, len(c.names))}
+
+	// 第1引数が context.Context の場合は、2番目以降の引数を対象とする
+	offset := 0
+	if fn != nil && fn.NumIn() > 0 && fn.In(0) == contextType {
+		offset = 1
+	}
+
+	// 型制約の変換関数は、アクションの引数の型の値を返却する関数へ置き換える (ex: :id<int> を int64 の引数へ渡す)
+	for i, typ := range c.types {
+		if typ == "" || fn == nil || i+offset >= fn.NumIn() {
+			continue
+		}
+		conv, ok := constraints[typ].typed(fn.In(i + offset))
+		if !ok {
+			return nil, &InvalidArgument{
+				Message: fmt.Sprintf("'%s' - constraint '%s' of '%s' cannot be used as type %s", path, typ, c.names[i], fn.In(i+offset)),
+				Path:    path,
+			}
+		}
+		p.converters[i] = conv
+	}
+
+	for i, name := range c.names {
+		if v, ok := defaults[name]; ok {
+			// 文字列で指定された既定値は、型制約に従い変換する
+			if s, ok := v.(string); ok && p.converters[i] != nil {
+				converted, err := p.converters[i](s)
+				if err != nil {
+					return nil, &InvalidArgument{
+						Message: fmt.Sprintf("'%s' - invalid default value '%s' for '%s'. %s", path, s, name, err),
+						Path:    path,
+						Err:     err,
+					}
+				}
+				v = converted
+			}
+			p.defaults[i] = reflect.ValueOf(v)
+			// JSON 形式から読み込んだ数値 (float64) などは、アクションの引数の型へ変換する
+			if fn != nil && i+offset < fn.NumIn() {
+				converted, ok := convertValue(p.defaults[i], fn.In(i+offset))
+				if !ok {
+					return nil, &InvalidArgument{
+						Message: fmt.Sprintf("'%s' - invalid default value '%v' for '%s'. cannot use (type %T) as type %s", path, v, name, v, fn.In(i+offset)),
+						Path:    path,
+					}
+				}
+				p.defaults[i] = converted
+			}
+			continue
+		}
+		if !c.optional[i] {
+			continue
+		}
+		if fn != nil && i+offset < fn.NumIn() {
+			p.defaults[i] = reflect.Zero(fn.In(i + offset))
+		}
+	}
+	return p, nil
+}
+
+// convertValue : v を型 typ の値へ変換する。数値型の間の変換は、値が変わらない場合のみ変換可能とする
+// nil は typ のゼロ値とする。変換できない場合は false を返却する
+func convertValue(v reflect.Value, typ reflect.Type) (reflect.Value, bool) {
+	if !v.IsValid() {
+		return reflect.Zero(typ), true
+	}
+	if v.Type().AssignableTo(typ) {
+		return v, true
+	}
+	// 文字列と数値の間の変換 (ex: string(rune(65))) は行わない
+	if numericKind(v.Kind()) != numericKind(typ.Kind()) || !v.Type().ConvertibleTo(typ) {
+		return reflect.Value{}, false
+	}
+	// 負の値は符号なし整数型へ変換しない
+	if unsignedKind(typ.Kind()) && (signedKind(v.Kind()) && v.Int() < 0 || floatKind(v.Kind()) && v.Float() < 0) {
+		return reflect.Value{}, false
+	}
+	converted := v.Convert(typ)
+	if numericKind(v.Kind()) && converted.Convert(v.Type()).Interface() != v.Interface() {
+		return reflect.Value{}, false
+	}
+	return converted, true
+}
+
+// numericKind : 整数型、浮動小数点数型の場合 true を返却する
+func numericKind(kind reflect.Kind) bool {
+	return signedKind(kind) || unsignedKind(kind) || floatKind(kind)
+}
+
+func signedKind(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Int64
+}
+
+func unsignedKind(kind reflect.Kind) bool {
+	return kind >= reflect.Uint && kind <= reflect.Uintptr
+}
+
+func floatKind(kind reflect.Kind) bool {
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
+
+// convert : 抜き出した値を変換する。変換できない場合はパスにマッチしなかったものとして扱う
+func (p *pattern) convert(i int, v string) (interface{}, error) {
+	if i < len(p.converters) && p.converters[i] != nil {
+		return p.converters[i](v)
+	}
+	return v, nil
+}
+
+// value : 省略されたパラメータの値を返却する
+func (p *pattern) value(i int) reflect.Value {
+	if i < len(p.defaults) && p.defaults[i].IsValid() {
+		return p.defaults[i]
+	}
+	return reflect.ValueOf("")
+}
+
+func tokenError(path string, tok token, format string, args ...interface{}) *ParseError {
+	return &ParseError{
+		Message: fmt.Sprintf("'%s' - %s at column %d", path, fmt.Sprintf(format, args...), tok.column),
+		Path:    path,
+		Column:  tok.column,
+	}
+}
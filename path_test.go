@@ -0,0 +1,293 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type Posts struct{}
+
+func (c *Posts) Show(year, slug string) string { return year + ":" + slug }
+func (c *Posts) Index(id string) string        { return "id:" + id }
+func (c *Posts) Detail(id string) string       { return "idx:" + id }
+func (c *Posts) Archive(year, month int, tag string) string {
+	return fmt.Sprintf("%d-%d-%s", year, month, tag)
+}
+
+func Test__PATH_PARSE(t *testing.T) {
+	r := New()
+	r.AddClass(Posts{})
+	r.AddRegexp("id", "([0-9]+)")
+	r.AddRegexp("idx", "([a-z]+)")
+	r.Register("GET", "/posts/{year:[0-9]{4}}/{slug}", "Posts.Show")
+	// :id と :idx は別のパラメータとして扱う
+	r.Register("GET", "/id/:id", "Posts.Index")
+	r.Register("GET", "/idx/:idx", "Posts.Detail")
+	r.Register("GET", "/{id}.json", "Posts.Index")
+	// {name} 形式は、正規表現のキャプチャグループ数によらず引数は1つとなる
+	r.AddRegexp("slug", "[a-z-]+")
+	r.AddRegexp("pair", "([a-z]+)-([a-z]+)")
+	r.Register("GET", "/slug/{slug}", "Posts.Index")
+	r.Register("GET", "/pair/{pair}", "Posts.Index")
+	router, err := r.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		path, result string
+	}{
+		{"/posts/2024/hello-world", "2024:hello-world"},
+		{"/id/10", "id:10"},
+		{"/idx/abc", "idx:abc"},
+		{"/10.json", "id:10"},
+		{"/slug/hello-world", "id:hello-world"},
+		{"/pair/ab-cd", "id:ab-cd"},
+	}
+	for _, test := range tests {
+		caller, args, err := router.Caller("GET", test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := caller.Call(args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result[0].String() != test.result {
+			t.Fatal("Call: Error", test.path, result[0].String())
+		}
+	}
+	// 固定文字列は正規表現として解釈しない
+	for _, path := range []string{"/posts/24/hello", "/posts/2024/a/b", "/idx/10", "/10xjson"} {
+		if _, _, err := router.Caller("GET", path); err == nil {
+			t.Fatal("Caller: Error", path)
+		}
+	}
+}
+
+func Test__PATH_ERROR(t *testing.T) {
+	var tests = []struct {
+		path   string
+		column int
+	}{
+		{"/users/:", 8},
+		{"/users/:/edit", 8},
+		{"/users/:id<int", 11},
+		{"/users/:id<>", 11},
+		{"/users/:id<float>", 8},
+		{"/users/:name", 8},
+		{"/users/{", 8},
+		{"/users/{}", 8},
+		{"/users/{-id}", 9},
+		{"/users/{id", 8},
+		{"/users/{id-x}", 11},
+		{"/users/{id:[0-9]{2}", 8},
+		{"/users/{id:}", 12},
+		{"/users/{id:[0-9}", 12},
+		{"/users/{id:([0-9]+)}", 12},
+		{"/users/}", 8},
+		{"/users/(.+", 8},
+		{"/ユーザー/{id", 7},
+		{"/archive()", 9},
+		{"/archive(/:id))", 15},
+		{"/archive(/:id(/:id)", 9},
+		{"/archive(/{id:[0-9]+)", 11},
+		{"/\xc4/:id", 2},
+	}
+	for _, test := range tests {
+		r := New()
+		r.AddClass(Posts{})
+		r.AddRegexp("id", "([0-9]+)")
+		r.Register("GET", test.path, "Posts.Index")
+		_, err := r.Create()
+		v, ok := err.(*ParseError)
+		if !ok {
+			t.Fatal("Create: Error", test.path, err)
+		}
+		if v.Column != test.column || v.Path != test.path {
+			t.Fatal("Create: Error", test.path, v.Column, err)
+		}
+		fmt.Println(err)
+	}
+}
+
+func Test__PATH_LEGACY(t *testing.T) {
+	// :name 形式のパラメータを含むパスの固定文字列に、正規表現の構文を含む場合はエラーとなる
+	r := New()
+	r.AddClass(Posts{})
+	r.AddRegexp("id", "([0-9]+)")
+	for path, column := range map[string]int{"/users/:id/(edit|show)": 17, "/users/:id/x+": 13, "/[a-z]+/:id": 2} {
+		err := r.Register("GET", path, "Posts.Index")
+		var v *ParseError
+		if !errors.As(err, &v) || !errors.Is(err, ErrInvalidPath) || v.Column != column || v.Method != "GET" {
+			t.Fatal("Register: Error", path, err)
+		}
+	}
+
+	// '\' でエスケープした記号、パラメータを含まないパスの記号は固定文字列となる
+	r.Register("GET", "/users/:id/x\\+", "Posts.Index")
+	r.Register("GET", "/c++/{id}", "Posts.Index")
+	r.Register("GET", "/files\\(1\\)\\{x\\}", "Posts.Detail")
+	router, err := r.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, action := range map[string]string{"/users/1/x+": "Index", "/c++/10": "Index", "/files(1){x}": "Detail"} {
+		caller, _, err := router.Caller("GET", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, actname := caller.Name(); actname != action {
+			t.Fatal("Caller: Error", path, actname)
+		}
+	}
+	if _, _, err := router.Caller("GET", "/users/1/xx"); err == nil {
+		t.Fatal("Caller: Error")
+	}
+}
+
+func Test__PATH_OPTIONAL(t *testing.T) {
+	r := New()
+	r.AddClass(Posts{})
+	r.AddRegexp("tag", "([a-z]+)")
+	if err := r.RegisterDefaults("GET", "/archive(/:year<int>(/:month<int>))(/tag/:tag)", "Posts.Archive", map[string]interface{}{
+		"year": "2024",
+		"tag":  "all",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// 既定値を指定しない場合は、ゼロ値となる
+	r.Register("GET", "/posts(/{year:[0-9]{4}}(/{slug}))", "Posts.Show")
+	router, err := r.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		path, result string
+	}{
+		{"/archive", "2024-0-all"},
+		{"/archive/2020", "2020-0-all"},
+		{"/archive/2020/5", "2020-5-all"},
+		{"/archive/tag/go", "2024-0-go"},
+		{"/archive/2020/5/tag/go", "2020-5-go"},
+		{"/posts", ":"},
+		{"/posts/2020", "2020:"},
+		{"/posts/2020/hello", "2020:hello"},
+	}
+	for _, test := range tests {
+		caller, args, err := router.Caller("GET", test.path)
+		if err != nil {
+			t.Fatal(test.path, err)
+		}
+		result, err := caller.Call(args)
+		if err != nil {
+			t.Fatal(test.path, err)
+		}
+		if result[0].String() != test.result {
+			t.Fatal("Call: Error", test.path, result[0].String())
+		}
+	}
+	for _, path := range []string{"/archive/", "/archive/x", "/posts/hello", "/archive/2020/tag"} {
+		if _, _, err := router.Caller("GET", path); err == nil {
+			t.Fatal("Caller: Error", path)
+		}
+	}
+
+	// 既定値が型制約に従い変換できない場合はエラーとなる
+	r = New()
+	r.AddClass(Posts{})
+	r.RegisterDefaults("GET", "/archive(/:year<int>)", "Posts.Archive", map[string]interface{}{"year": "now"})
+	if _, err := r.Create(); err == nil {
+		t.Fatal("Create: Error")
+	}
+
+	// JSON 形式で読み込んだ数値の既定値 (float64) は、アクションの引数の型へ変換する
+	r = New()
+	r.AddRegexp("tag", "([a-z]+)")
+	r.RegisterDefaults("GET", "/archive(/:year<int>(/:month<int>))(/tag/:tag)", "Posts.Archive", map[string]interface{}{"year": 2024})
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := New()
+	if err := json.Unmarshal(data, loaded); err != nil {
+		t.Fatal(err)
+	}
+	loaded.AddClass(Posts{})
+	router, err = loaded.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	caller, args, err := router.Caller("GET", "/archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result, err := caller.Call(args); err != nil || result[0].String() != "2024-0-" {
+		t.Fatal("Call: Error", result, err)
+	}
+
+	// 引数の型へ変換できない既定値は、Create でエラーとなる
+	for _, v := range []interface{}{2024.5, "2024", []int{2024}} {
+		r = New()
+		r.AddClass(Posts{})
+		r.AddRegexp("year", "([0-9]+)")
+		r.AddRegexp("tag", "([a-z]+)")
+		r.RegisterDefaults("GET", "/archive(/:year(/:month<int>))(/tag/:tag)", "Posts.Archive", map[string]interface{}{"year": v})
+		if _, err := r.Create(); !errors.Is(err, ErrInvalidArgument) {
+			t.Fatal("Create: Error", v, err)
+		}
+	}
+}
+
+func Test__PATH_BUILD(t *testing.T) {
+	r := New()
+	r.AddRegexp("pair", "([a-z]+)-([a-z]+)")
+	var tests = []struct {
+		path   string
+		params map[string]interface{}
+		result string
+	}{
+		{"/users", nil, "/users"},
+		{"/users/:id<int>", map[string]interface{}{"id": 10}, "/users/10"},
+		{"/files/{name}", map[string]interface{}{"name": "a b/c%d"}, "/files/a%20b%2Fc%25d"},
+		{"/days/:d<date>", map[string]interface{}{"d": time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)}, "/days/2024-02-29"},
+		{"/:pair", map[string]interface{}{"pair": "ab-cd"}, "/ab-cd"},
+		{"/archive(/:year<int>(/:month<int>))", nil, "/archive"},
+		{"/archive(/:year<int>(/:month<int>))", map[string]interface{}{"year": 2020}, "/archive/2020"},
+		{"/archive(/:year<int>(/:month<int>))", map[string]interface{}{"year": 2020, "month": 5}, "/archive/2020/5"},
+		{"/archive(/:year<int>(/:month<int>))", map[string]interface{}{"month": 5}, "/archive"},
+		{"/posts(/edit)", nil, "/posts"},
+	}
+	for _, test := range tests {
+		result, err := r.BuildPath(test.path, test.params)
+		if err != nil {
+			t.Fatal(test.path, err)
+		}
+		if result != test.result {
+			t.Fatal("BuildPath: Error", test.path, result)
+		}
+	}
+
+	// 値が指定されていない、または正規表現、型制約を満たさない場合はエラーとなる
+	for _, test := range []struct {
+		path   string
+		params map[string]interface{}
+	}{
+		{"/users/:id<int>", nil},
+		{"/users/:id<int>", map[string]interface{}{"id": "x"}},
+		{"/users/:id<int>", map[string]interface{}{"id": "99999999999999999999"}},
+		{"/users/{id:[0-9]+}", map[string]interface{}{"id": "1/2"}},
+		{"/archive(/:year<int>)", map[string]interface{}{"year": "now"}},
+		{"/:pair", map[string]interface{}{"pair": "ab"}},
+		{"/:none", map[string]interface{}{"none": "ab"}},
+		{"/users/(", nil},
+	} {
+		if _, err := r.BuildPath(test.path, test.params); err == nil {
+			t.Fatal("BuildPath: Error", test.path)
+		}
+	}
+}
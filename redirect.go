@@ -0,0 +1,212 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// Redirect : リダイレクトとして登録したルートパスへアクセスした場合に、Router.Caller が返却する Result
+// アクションは持たないため、Get、Call などはエラーを返却する
+//
+//	if rd, ok := action.(*router.Redirect); ok {
+//		http.Redirect(w, req, rd.Location, rd.Status)
+//	}
+type Redirect struct {
+	Location string // パラメータの値を埋め込んだリダイレクト先のパス
+	Status   int    // HTTP ステータスコード
+
+	target string      // リダイレクト先のパス
+	names  []string    // キャプチャグループ順のパラメータ名
+	raw    bool        // 抜き出した値がパーセントエンコードされたままか
+	table  *RouteTable // リダイレクト先のパスの生成に使用する正規表現
+}
+
+// redirectStatus : リダイレクトに使用可能な HTTP ステータスコード
+var redirectStatus = map[int]bool{
+	http.StatusMovedPermanently:  true,
+	http.StatusFound:             true,
+	http.StatusSeeOther:          true,
+	http.StatusTemporaryRedirect: true,
+	http.StatusPermanentRedirect: true,
+}
+
+// RegisterRedirect : コントローラを伴わない、リダイレクトのルートパスを登録する
+// target にはリダイレクト先のパスを指定し、path で抜き出したパラメータを同名のパラメータへ埋め込む。
+// status には 301、302、303、307、308 のいずれかを指定する
+// ex) r.RegisterRedirect("GET", "/u/:id", "/users/:id", http.StatusMovedPermanently)
+func (rt *RouteTable) RegisterRedirect(method, path, target string, status int) error {
+	if !redirectStatus[status] {
+		return &InvalidArgument{Message: fmt.Sprintf("'%s' - invalid redirect status. %d", path, status), Method: method, Path: path}
+	}
+	if !strings.HasPrefix(target, "/") {
+		return &InvalidPath{Message: fmt.Sprintf("'%s' - invalid redirect target '%s'", path, target), Method: method, Path: path}
+	}
+	return rt.setRoute(method, path, &Route{redirect: target, status: status})
+}
+
+// RegisterAlias : 別のルートパスと同じアクションを実行するルートパスを登録する
+// target には同じメソッドで登録したルートパスを指定する。アクションは Create 時に求めるため、登録順は問わない
+// ex) r.RegisterAlias("GET", "/u/:id", "/users/:id")
+func (rt *RouteTable) RegisterAlias(method, path, target string) error {
+	if target == "" {
+		return &InvalidPath{Message: fmt.Sprintf("'%s' - alias target is empty", path), Method: method, Path: path}
+	}
+	return rt.setRoute(method, path, &Route{alias: target})
+}
+
+// resolveAlias : 別名として登録したルートパスを、実行するルートパスへ解決する
+func (rt *RouteTable) resolveAlias(method, path string, route *Route) (*Route, error) {
+	seen := map[string]bool{path: true}
+	for route.alias != "" {
+		target, ok := rt.routes[method][route.alias]
+		if !ok {
+			return nil, &InvalidPath{
+				Message: fmt.Sprintf("'[%s]: %s' - alias target '%s' not registered", method, path, route.alias),
+				Method:  method,
+				Path:    path,
+			}
+		}
+		if seen[route.alias] {
+			return nil, &InvalidPath{
+				Message: fmt.Sprintf("'[%s]: %s' - alias loop at '%s'", method, path, route.alias),
+				Method:  method,
+				Path:    path,
+			}
+		}
+		seen[route.alias] = true
+		route = target
+	}
+	return route, nil
+}
+
+// redirectAction : リダイレクトのルートパスに対応する Redirect を生成する
+// リダイレクト先のパスで必須のパラメータは、path に含まれていること
+func (rt *RouteTable) redirectAction(path string, route *Route) (*Redirect, error) {
+	scope := rt.scope(route.ctlname)
+	rd := &Redirect{Location: route.redirect, Status: route.status, target: route.redirect, raw: rt.Options.RawCaptures}
+	if !route.prior {
+		c, err := scope.compile(path)
+		if err != nil {
+			return nil, err
+		}
+		rd.names = c.names
+	}
+
+	// リダイレクト先のパスの必須のパラメータを検証する
+	tokens, err := parsePath(route.redirect)
+	if err != nil {
+		return nil, err
+	}
+	depth := 0
+	for _, tok := range tokens {
+		switch {
+		case tok.kind == tokenOpen:
+			depth++
+		case tok.kind == tokenClose:
+			depth--
+		case tok.kind == tokenParam && depth == 0 && !containsString(rd.names, tok.name):
+			return nil, &InvalidPath{
+				Message: fmt.Sprintf("'%s' - redirect parameter ':%s' not found in '%s'", route.redirect, tok.name, path),
+				Path:    path,
+			}
+		}
+	}
+
+	// パラメータを含まない場合は、リダイレクト先のパスをそのまま使用する
+	if len(rd.names) == 0 {
+		location, err := scope.BuildPath(route.redirect, nil)
+		if err != nil {
+			return nil, err
+		}
+		rd.Location = location
+		return rd, nil
+	}
+	// 生成後の RouteTable の変更が影響しないよう、正規表現を複製する
+	table := *scope
+	table.regex = make(map[string]string, len(scope.regex))
+	for k, v := range scope.regex {
+		table.regex[k] = v
+	}
+	rd.table = &table
+	return rd, nil
+}
+
+// redirectResult : アクションがリダイレクトの場合は、抜き出した引数をリダイレクト先のパスへ埋め込んだ Redirect を返却する
+func redirectResult(action Result, args []reflect.Value) (Result, []reflect.Value, error) {
+	rd, ok := action.(*Redirect)
+	if !ok {
+		return action, args, nil
+	}
+	resolved, err := rd.resolve(args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resolved, nil, nil
+}
+
+// resolve : 抜き出した引数をリダイレクト先のパスへ埋め込んだ Redirect を返却する
+// 省略されたパラメータは、リダイレクト先のパスでも省略する
+func (rd *Redirect) resolve(args []reflect.Value) (*Redirect, error) {
+	if rd.table == nil {
+		return &Redirect{Location: rd.Location, Status: rd.Status}, nil
+	}
+	params := make(map[string]interface{})
+	for i, name := range rd.names {
+		if _, ok := params[name]; ok || i >= len(args) {
+			continue
+		}
+		v := args[i].Interface()
+		if s, ok := v.(string); ok {
+			if s == "" {
+				continue
+			}
+			if rd.raw {
+				unescaped, err := url.PathUnescape(s)
+				if err != nil {
+					return nil, err
+				}
+				v = unescaped
+			}
+		}
+		params[name] = v
+	}
+	location, err := rd.table.BuildPath(rd.target, params)
+	if err != nil {
+		return nil, err
+	}
+	return &Redirect{Location: location, Status: rd.Status}, nil
+}
+
+// Get : リダイレクトはアクションを持たないため、エラーを返却する
+func (rd *Redirect) Get() (reflect.Value, error) {
+	return reflect.Value{}, fmt.Errorf("'%s' - redirect has no action", rd.Location)
+}
+
+// Call : リダイレクトはアクションを持たないため、エラーを返却する
+func (rd *Redirect) Call(args []reflect.Value, ret ...string) ([]reflect.Value, error) {
+	return nil, fmt.Errorf("'%s' - redirect has no action", rd.Location)
+}
+
+// CallContext : リダイレクトはアクションを持たないため、エラーを返却する
+func (rd *Redirect) CallContext(ctx context.Context, args []reflect.Value, ret ...string) ([]reflect.Value, error) {
+	return rd.Call(args, ret...)
+}
+
+// Name : リダイレクトはコントローラ、アクションを持たないため、空文字列を返却する
+func (rd *Redirect) Name() (string, string) {
+	return "", ""
+}
+
+// Valid : リダイレクトはアクションを持たないため、エラーを返却する
+func (rd *Redirect) Valid(caller reflect.Value, args []reflect.Value, ret ...string) (reflect.Value, error) {
+	return reflect.Value{}, fmt.Errorf("'%s' - redirect has no action", rd.Location)
+}
+
+// Callname : リダイレクトはアクションを持たないため、エラーを返却する
+func (rd *Redirect) Callname(elem reflect.Value, methodname string, args []reflect.Value, ret ...string) ([]reflect.Value, error) {
+	return nil, fmt.Errorf("'%s' - redirect has no action", rd.Location)
+}
@@ -0,0 +1,241 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func Test__REDIRECT(t *testing.T) {
+	r := New()
+	r.AddRegexp("id", "([0-9]+)")
+	r.AddClass(Sample{})
+	r.Register("GET", "/users/:id", "Sample.TheTest")
+	r.RegisterRedirect("GET", "/old", "/users/1", http.StatusFound)
+	r.RegisterRedirect("GET", "/u/:id", "/users/:id", http.StatusMovedPermanently)
+	r.RegisterRedirect("GET", "/archive(/:year<int>)", "/posts(/:year<int>)", http.StatusPermanentRedirect)
+	r.RegisterRedirect("GET", "/find/{name}", "/search/{name}", http.StatusSeeOther)
+	router, err := r.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		path, location string
+		status         int
+	}{
+		{"/old", "/users/1", http.StatusFound},
+		{"/u/10", "/users/10", http.StatusMovedPermanently},
+		{"/archive/2020", "/posts/2020", http.StatusPermanentRedirect},
+		{"/archive", "/posts", http.StatusPermanentRedirect},
+		{"/find/a%20b", "/search/a%20b", http.StatusSeeOther},
+	}
+	for _, test := range tests {
+		action, args, err := router.Caller("GET", test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rd, ok := action.(*Redirect)
+		if !ok || rd.Location != test.location || rd.Status != test.status || args != nil {
+			t.Fatal("Caller: Error", test.path, action)
+		}
+		// リダイレクトはアクションを持たない
+		if _, err := rd.Call(nil); err == nil {
+			t.Fatal("Call: Error")
+		}
+		if _, err := rd.Get(); err == nil {
+			t.Fatal("Get: Error")
+		}
+		if ctlname, actname := rd.Name(); ctlname != "" || actname != "" {
+			t.Fatal("Name: Error", ctlname, actname)
+		}
+	}
+
+	// 返却された Redirect を変更しても、他の呼び出しへ影響しない
+	action, _, _ := router.Caller("GET", "/old")
+	action.(*Redirect).Location = "/changed"
+	if action, _, _ := router.Caller("GET", "/old"); action.(*Redirect).Location != "/users/1" {
+		t.Fatal("Caller: Error", action)
+	}
+
+	// RawCaptures の場合も、パーセントエンコードを二重に行わない
+	r.Options.RawCaptures = true
+	router, _ = r.Create()
+	if action, _, err := router.Caller("GET", "/find/a%2Fb"); err != nil || action.(*Redirect).Location != "/search/a%2Fb" {
+		t.Fatal("Caller: Error", action, err)
+	}
+}
+
+func Test__REDIRECT_ERROR(t *testing.T) {
+	r := New()
+	if err := r.RegisterRedirect("GET", "/u", "/users", http.StatusOK); err == nil {
+		t.Fatal("RegisterRedirect: Error")
+	}
+	if err := r.RegisterRedirect("GET", "/u", "users", http.StatusFound); !errors.Is(err, ErrInvalidPath) {
+		t.Fatal("RegisterRedirect: Error", err)
+	}
+	if err := r.RegisterRedirect("GET", "", "/users", http.StatusFound); !errors.Is(err, ErrInvalidPath) {
+		t.Fatal("RegisterRedirect: Error", err)
+	}
+
+	// リダイレクト先の必須のパラメータが、パスに含まれていない場合
+	r.AddRegexp("id", "([0-9]+)")
+	r.RegisterRedirect("GET", "/u", "/users/:id", http.StatusFound)
+	_, err := r.Create()
+	var invalid *InvalidPath
+	if !errors.As(err, &invalid) || invalid.Method != "GET" || invalid.Path != "/u" {
+		t.Fatal("Create: Error", err)
+	}
+	if errs := r.Lint(); len(errs) != 1 {
+		t.Fatal("Lint: Error", errs)
+	}
+}
+
+func Test__ALIAS(t *testing.T) {
+	r := New()
+	r.AddRegexp("id", "([0-9]+)")
+	r.AddClass(Sample{})
+	// 参照先より先に登録可能
+	if err := r.RegisterAlias("GET", "/m/:id", "/u/:id"); err != nil {
+		t.Fatal(err)
+	}
+	r.RegisterAlias("GET", "/u/:id", "/users/:id")
+	r.RegisterMeta("GET", "/users/:id", "Sample.TheTest", Meta{Name: "user", Auth: []string{"login"}})
+	r.RegisterAlias("GET", "/r", "/old")
+	r.RegisterRedirect("GET", "/old", "/users/1", http.StatusFound)
+	if err := r.RegisterAlias("GET", "/x", ""); err == nil {
+		t.Fatal("RegisterAlias: Error")
+	}
+
+	for _, create := range []func() (Router, error){r.Create, r.CreateDry} {
+		router, err := create()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 別名は、参照先のアクション、メタデータを使用する
+		for _, path := range []string{"/users/10", "/u/10", "/m/10"} {
+			action, args, err := router.Caller("GET", path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ctlname, actname := action.Name(); ctlname != "Sample" || actname != "TheTest" || args[0].String() != "10" {
+				t.Fatal("Caller: Error", path, ctlname, actname, args)
+			}
+			if meta := MetaOf(action); meta == nil || meta.Auth[0] != "login" {
+				t.Fatal("MetaOf: Error", path, meta)
+			}
+		}
+		// リダイレクトの別名は、リダイレクトとなる
+		if action, _, err := router.Caller("GET", "/r"); err != nil || action.(*Redirect).Location != "/users/1" {
+			t.Fatal("Caller: Error", action, err)
+		}
+	}
+	// ルート名は参照先のみに付与される
+	if info, _ := r.Route("user"); info.Path != "/users/:id" {
+		t.Fatal("Route: Error", info)
+	}
+
+	// 参照先が存在しない、循環している場合
+	for _, target := range []string{"/undefined", "/loop"} {
+		r := New()
+		r.RegisterAlias("GET", "/loop", "/a")
+		r.RegisterAlias("GET", "/a", target)
+		if _, err := r.Create(); !errors.Is(err, ErrInvalidPath) {
+			t.Fatal("Create: Error", target, err)
+		}
+		if errs := r.Lint(); len(errs) == 0 {
+			t.Fatal("Lint: Error", target)
+		}
+	}
+}
+
+func Test__REDIRECT_TABLE(t *testing.T) {
+	r := New()
+	r.AddRegexp("id", "([0-9]+)")
+	r.AddClass(Users{})
+	r.Register("GET", "/users/:id<int>", "Users.Show")
+	r.RegisterRedirect("GET", "/u/:id<int>", "/users/:id<int>", http.StatusMovedPermanently)
+	r.RegisterAlias("GET", "/members/:id<int>", "/users/:id<int>")
+
+	want := [][]string{
+		{"GET", "/members/:id<int>", "ALIAS /users/:id<int>"},
+		{"GET", "/u/:id<int>", "REDIRECT 301 /users/:id<int>"},
+		{"GET", "/users/:id<int>", "Users.Show"},
+	}
+	for _, row := range want {
+		if got := r.GetRouter(row[0], row[1]); got != row[2] {
+			t.Fatal("GetRouter: Error", row, got)
+		}
+	}
+	if list := r.TableList(); len(list["ROUTER"]) != 3 {
+		t.Fatal("TableList: Error", list)
+	}
+
+	// JSON 形式、テキスト形式で出力、読み込みが可能
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"redirect":"/users/:id\u003cint\u003e","status":301}`) {
+		t.Fatal("MarshalJSON: Error", string(data))
+	}
+	var text strings.Builder
+	for _, row := range want {
+		text.WriteString(strings.Join(row, " ") + "\n")
+	}
+	for _, input := range []string{string(data), text.String()} {
+		loaded := New()
+		if err := loaded.Load(strings.NewReader(input)); err != nil {
+			t.Fatal(err)
+		}
+		for _, row := range want {
+			if got := loaded.GetRouter(row[0], row[1]); got != row[2] {
+				t.Fatal("Load: Error", row, got)
+			}
+		}
+	}
+	if err := New().Load(strings.NewReader("GET /u REDIRECT moved /users")); err == nil || !strings.HasPrefix(err.Error(), "line 1:") {
+		t.Fatal("Load: Error", err)
+	}
+
+	// OpenAPI には、リダイレクト、別名のルートパスを含めない
+	doc, err := r.OpenAPI("Users API", "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Paths) != 1 || doc.Paths["/users/{id}"] == nil {
+		t.Fatal("OpenAPI: Error", doc.Paths)
+	}
+}
+
+func Test__REDIRECT_MOUNT(t *testing.T) {
+	sub := New()
+	sub.AddRegexp("id", "([a-z]+)")
+	sub.AddClass(Sample{})
+	sub.Register("GET", "/invoices/:id", "Sample.TheTest")
+	sub.RegisterRedirect("GET", "/i/:id", "/invoices/:id", http.StatusMovedPermanently)
+	sub.RegisterAlias("GET", "/bills/:id", "/invoices/:id")
+
+	r := New()
+	r.AddRegexp("id", "([0-9]+)")
+	if err := r.Mount("billing", "/billing", sub); err != nil {
+		t.Fatal(err)
+	}
+	router, err := r.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// リダイレクト先、別名の参照先は、マウントしたパス配下となる
+	if action, _, err := router.Caller("GET", "/billing/i/abc"); err != nil || action.(*Redirect).Location != "/billing/invoices/abc" {
+		t.Fatal("Caller: Error", action, err)
+	}
+	action, _, err := router.Caller("GET", "/billing/bills/abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctlname, actname := action.Name(); ctlname != "billing:Sample" || actname != "TheTest" {
+		t.Fatal("Caller: Error", ctlname, actname)
+	}
+}
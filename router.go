@@ -1,10 +1,14 @@
 package router
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"regexp"
+	"runtime/debug"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/ochipin/router/trie"
 )
@@ -12,9 +16,11 @@ import (
 // New : ルーティングテーブル設定用構造体を生成する
 func New() *RouteTable {
 	rt := &RouteTable{
-		regex:   make(map[string]string),
-		classes: make(map[string]interface{}),
-		routes:  make(map[string]map[string]*Route),
+		regex:     make(map[string]string),
+		classes:   make(map[string]interface{}),
+		factories: make(map[string]Factory),
+		routes:    make(map[string]map[string]*Route),
+		mounts:    make(map[string]*RouteTable),
 	}
 	rt.Generator = rt
 	return rt
@@ -22,17 +28,41 @@ func New() *RouteTable {
 
 // Route : ルーティングパスの情報を取り扱う構造体
 type Route struct {
-	ctlname string // コントローラ名
-	actname string // アクション名
-	prior   bool   // 処理優先度。正規表現を使用されていた場合、優先度は低となる
+	ctlname string        // コントローラ名
+	actname string        // アクション名
+	prior   bool          // 処理優先度。正規表現を使用されていた場合、優先度は低となる
+	timeout time.Duration // アクションの実行制限時間。0 の場合は RouteTable.Timeout に従う
+	// 省略可能なパラメータが省略された場合に渡す値
+	defaults map[string]interface{}
+	meta     *Meta  // ルートパスのメタデータ
+	redirect string // リダイレクト先のパス。リダイレクトのルートパスの場合のみ
+	status   int    // リダイレクトの HTTP ステータスコード
+	alias    string // 同じアクションを実行するルートパス。別名のルートパスの場合のみ
+}
+
+// action : ルートパスが実行する内容を、Controller.Action 形式、またはリダイレクト、別名の形式で返却する
+// ex) Users.Show, REDIRECT 301 /users/:id, ALIAS /users/:id
+func (route *Route) action() string {
+	switch {
+	case route.redirect != "":
+		return fmt.Sprintf("REDIRECT %d %s", route.status, route.redirect)
+	case route.alias != "":
+		return "ALIAS " + route.alias
+	}
+	return route.ctlname + "." + route.actname
 }
 
 // RouteTable : ルーティングテーブル設定構造体
 type RouteTable struct {
 	regex     map[string]string            // 正規表現登録用オブジェクト
 	classes   map[string]interface{}       // 構造体登録用オブジェクト
+	factories map[string]Factory           // コントローラ生成関数登録用オブジェクト
 	routes    map[string]map[string]*Route // ルーティングパス登録用オブジェクト
+	mounts    map[string]*RouteTable       // 名前空間ごとにマウントした RouteTable
 	Generator Generator
+	Timeout   time.Duration // 全ルート共通のアクション実行制限時間。0 の場合は無制限
+	Container *Container    // コントローラへ注入するサービス
+	Options   Options       // パスの照合方法
 }
 
 // MixinClass : 指定したコントローラがミックスインされているか確認する
@@ -50,15 +80,17 @@ func (rt *RouteTable) MixinClass(i interface{}, name string) bool {
 	}
 
 	// 型情報を取得する
-	typ := reflect.TypeOf(i)
+	typ := structType(reflect.TypeOf(i))
+	if typ == nil {
+		return false
+	}
 	// 指定された型情報が存在しない場合はfalseを返却する
-	v, ok := rt.classes[typ.Name()]
-	if !ok {
+	if _, ok := rt.classes[classKey(typ)]; !ok {
 		return false
 	}
 
 	// 型名とnameが一致した場合はtrueを返却する
-	val := reflect.ValueOf(v)
+	val := reflect.New(typ).Elem()
 	if val.Type().String() == name {
 		return true
 	}
@@ -83,15 +115,66 @@ func (rt *RouteTable) BoolClass(i interface{}) bool {
 		return false
 	}
 
-	typ := reflect.TypeOf(i)
-	if typ.Kind() != reflect.Struct {
+	typ := structType(reflect.TypeOf(i))
+	if typ == nil {
 		return false
 	}
-	if v, ok := rt.classes[typ.Name()]; ok {
-		return reflect.TypeOf(v).String() == typ.String()
+	_, ok := rt.classes[classKey(typ)]
+	return ok
+}
+
+// structType : 構造体、または構造体へのポインタの場合、構造体の型情報を返却する。それ以外は nil を返却する
+func structType(typ reflect.Type) reflect.Type {
+	if typ == nil {
+		return nil
 	}
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+	return typ
+}
 
-	return false
+// classKey : コントローラ登録用のキー名を返却する (ex: github.com/ochipin/router.Sample)
+func classKey(typ reflect.Type) string {
+	return typ.PkgPath() + "." + typ.Name()
+}
+
+// lookupClass : コントローラ名に該当する登録済みのコントローラを返却する
+// ctlname には、パッケージパス付きの名前(github.com/ochipin/router.Sample)、
+// パッケージ名付きの名前(router.Sample)、または構造体名(Sample)を指定可能
+func (rt *RouteTable) lookupClass(ctlname string) (string, interface{}, error) {
+	// 名前空間が付与されている場合は、マウントした RouteTable のコントローラを検索する
+	classes := rt.scope(ctlname).classes
+	_, name := splitNamespace(ctlname)
+	if v, ok := classes[name]; ok {
+		return name, v, nil
+	}
+
+	var keys []string
+	for key, v := range classes {
+		typ := structType(reflect.TypeOf(v))
+		if typ.String() == name || typ.Name() == name {
+			keys = append(keys, key)
+		}
+	}
+	switch len(keys) {
+	case 0:
+		return "", nil, &ControllerNotRegistered{
+			Message:    fmt.Sprintf("'%s' - controller not registered", ctlname),
+			Controller: ctlname,
+		}
+	case 1:
+		return keys[0], classes[keys[0]], nil
+	}
+	sort.Strings(keys)
+	return "", nil, &AmbiguousController{
+		Message:    fmt.Sprintf("'%s' - ambiguous controller name. candidates: %s", ctlname, strings.Join(keys, ", ")),
+		Controller: ctlname,
+		Candidates: keys,
+	}
 }
 
 // GetRegexp : 登録されている正規表現情報を返却する
@@ -118,7 +201,7 @@ func (rt *RouteTable) GetRouter(method, path string) string {
 		return ""
 	}
 
-	return route.ctlname + "." + route.actname
+	return route.action()
 }
 
 // TableList : 登録されているルート情報を返却する
@@ -129,6 +212,12 @@ func (rt *RouteTable) TableList() map[string][][]string {
 	for k, v := range rt.regex {
 		list["REGEXP"] = append(list["REGEXP"], []string{k, v})
 	}
+	// マウントした RouteTable の正規表現は、名前空間を付与する (ex: billing:id)
+	rt.eachMount(func(ns string, sub *RouteTable) {
+		for k, v := range sub.regex {
+			list["REGEXP"] = append(list["REGEXP"], []string{ns + k, v})
+		}
+	})
 	if _, ok := list["REGEXP"]; !ok {
 		list["REGEXP"] = [][]string{}
 	}
@@ -136,13 +225,26 @@ func (rt *RouteTable) TableList() map[string][][]string {
 	// ルーティングテーブル情報を取得する
 	for m, v := range rt.routes {
 		for p, route := range v {
-			list["ROUTER"] = append(list["ROUTER"], []string{m, p, route.ctlname + "." + route.actname})
+			list["ROUTER"] = append(list["ROUTER"], []string{m, p, route.action()})
 		}
 	}
 	if _, ok := list["ROUTER"]; !ok {
 		list["ROUTER"] = [][]string{}
 	}
 
+	// メタデータが付与されている場合は、メタデータ情報を取得する
+	// 名前、説明、タグ、認証、レート制限、非推奨、任意の値の順とする
+	for m, v := range rt.routes {
+		for p, route := range v {
+			if route.meta != nil {
+				list["META"] = append(list["META"], append([]string{m, p}, route.meta.row()...))
+			}
+		}
+	}
+	if _, ok := list["META"]; !ok {
+		list["META"] = [][]string{}
+	}
+
 	return list
 }
 
@@ -178,7 +280,12 @@ func (rt *RouteTable) AddRegexp(id, regex string) error {
 		return fmt.Errorf("key name is empty")
 	}
 	if _, err := regexp.Compile(regex); err != nil {
-		return fmt.Errorf("'%s' - invalid regexp. '%s' not used", id, regex)
+		return &InvalidRegexp{
+			Message: fmt.Sprintf("'%s' - invalid regexp. '%s' not used", id, regex),
+			Name:    id,
+			Regexp:  regex,
+			Err:     err,
+		}
 	}
 	rt.regex[":"+id] = regex
 	return nil
@@ -201,163 +308,454 @@ func (rt *RouteTable) AddClass(i interface{}) error {
 	if typ == nil {
 		return fmt.Errorf("invalid argument. is nil")
 	}
-	// 構造体形式ではない場合、エラーを返却する
+	// 構造体、または構造体へのポインタ形式ではない場合、エラーを返却する
+	styp := structType(typ)
+	if styp == nil {
+		return fmt.Errorf("invalid argument. '%s' is not struct", typ.String())
+	}
+	if rt.factories == nil {
+		rt.factories = make(map[string]Factory)
+	}
+	// 与えられた構造体を、パッケージパス付きの型名で登録する
+	key := classKey(styp)
+	rt.classes[key] = i
+	delete(rt.factories, key)
+	return nil
+}
+
+// AddConstructor : コントローラを生成する関数を登録する
+// fn は func() T、func() *T、func() (T, error)、func() (*T, error) のいずれかの形式で、T は構造体であること
+func (rt *RouteTable) AddConstructor(fn interface{}) error {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return fmt.Errorf("invalid argument. constructor is not function")
+	}
+	ftyp := v.Type()
+	if ftyp.NumIn() != 0 || ftyp.NumOut() == 0 || ftyp.NumOut() > 2 ||
+		(ftyp.NumOut() == 2 && ftyp.Out(1) != errorType) {
+		return fmt.Errorf("invalid argument. '%s' is not constructor", ftyp)
+	}
+	// 生成する構造体の型を取得する
+	typ := ftyp.Out(0)
+	ptr := typ.Kind() == reflect.Ptr
+	if ptr {
+		typ = typ.Elem()
+	}
 	if typ.Kind() != reflect.Struct {
 		return fmt.Errorf("invalid argument. '%s' is not struct", typ.Name())
 	}
-	// 与えられた構造体を登録する
-	rt.classes[typ.Name()] = i
+	if err := rt.AddClass(reflect.Zero(typ).Interface()); err != nil {
+		return err
+	}
+	// 生成関数の復帰値を、構造体へのポインタとして返却する関数を登録する
+	rt.factories[classKey(typ)] = func() (reflect.Value, error) {
+		out := v.Call(nil)
+		if len(out) == 2 && !out[1].IsNil() {
+			return reflect.Value{}, out[1].Interface().(error)
+		}
+		if ptr {
+			if out[0].IsNil() {
+				return reflect.Value{}, fmt.Errorf("'%s' - constructor returned nil", typ.Name())
+			}
+			return out[0], nil
+		}
+		caller := reflect.New(typ)
+		caller.Elem().Set(out[0])
+		return caller, nil
+	}
+	return nil
+}
+
+// AddPrototype : コントローラを登録する。アクセス時には i のコピーを生成して実行する
+// コピーはシャローコピーのため、ポインタ、マップ、スライスなどのフィールドは各インスタンスで共有される
+func (rt *RouteTable) AddPrototype(i interface{}) error {
+	if err := rt.AddClass(i); err != nil {
+		return err
+	}
+	v := reflect.ValueOf(i)
+	// ポインタの場合は、指し示す構造体をコピー元とする
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return fmt.Errorf("invalid argument. '%s' is nil", v.Type())
+		}
+		v = v.Elem()
+	}
+	rt.factories[classKey(v.Type())] = func() (reflect.Value, error) {
+		caller := reflect.New(v.Type())
+		caller.Elem().Set(v)
+		return caller, nil
+	}
 	return nil
 }
 
 // Register : ルートパスを登録する
 func (rt *RouteTable) Register(method, path, name string) error {
 	// コントローラ名、アクション名を抜き出す
-	names := strings.Split(name, ".")
-	if len(names) != 2 {
+	// 最後の '.' 以降をアクション名とするため、router.Sample.Index のようにパッケージ名を付与可能
+	idx := strings.LastIndex(name, ".")
+	if idx <= 0 || idx == len(name)-1 {
 		if name == "" {
-			return fmt.Errorf("controller.action name is empty")
+			return &InvalidArgument{Message: "controller.action name is empty", Method: method, Path: path}
 		}
-		return fmt.Errorf("'%s' - invalid controller.action name", name)
+		return &InvalidArgument{Message: fmt.Sprintf("'%s' - invalid controller.action name", name), Method: method, Path: path}
 	}
+	names := []string{name[:idx], name[idx+1:]}
+
+	return rt.setRoute(method, path, &Route{ctlname: names[0], actname: names[1]})
+}
 
+// setRoute : ルーティングテーブルへルートパスを登録する
+func (rt *RouteTable) setRoute(method, path string, route *Route) error {
 	// path が空文字列の場合はエラーを返却する
 	if path == "" {
-		return fmt.Errorf("path is empty")
+		return &InvalidPath{Message: "path is empty", Method: method, Controller: route.ctlname}
+	}
+	// 正規表現の構文が、正規表現として解釈されないまま登録されないようエラーとする
+	if err := legacyRegexp(path); err != nil {
+		return withRoute(err, method, path)
 	}
 
-	// プライオリティ値を図る
-	prior := strings.Count(path, ":") == 0
+	// プライオリティ値を図る。パラメータなどを含む場合は、正規表現形式のパスとして扱う
+	route.prior = !strings.ContainsAny(path, reserved)
+
+	// 同じアクションが登録済みの場合は、実行制限時間、既定値、メタデータを引き継ぐため置き換えない
+	if rt.registered(method, path, route) != nil {
+		return nil
+	}
 
 	// GET, POSTなどのリクエストメソッドを受け取る箱がない場合は作成する
 	if _, ok := rt.routes[method]; !ok {
 		rt.routes[method] = make(map[string]*Route)
 	}
 	// ルーティングテーブルを作成する
-	rt.routes[method][path] = &Route{
-		ctlname: names[0],
-		actname: names[1],
-		prior:   prior,
+	rt.routes[method][path] = route
+
+	return nil
+}
+
+// registered : 同じメソッド、パスへ登録済みの、route と同じアクションの Route を返却する。存在しない場合は nil を返却する
+// リダイレクト、別名のルートパスは対象外とする
+func (rt *RouteTable) registered(method, path string, route *Route) *Route {
+	old, ok := rt.routes[method][path]
+	if !ok || old.redirect != "" || old.alias != "" || route.redirect != "" || route.alias != "" {
+		return nil
 	}
+	if old.ctlname != route.ctlname || old.actname != route.actname {
+		return nil
+	}
+	return old
+}
 
+// RegisterTimeout : 実行制限時間付きでルートパスを登録する
+// 同じアクションで登録済みのルートパスの場合は、既定値、メタデータを保持したまま実行制限時間を設定する
+func (rt *RouteTable) RegisterTimeout(method, path, name string, timeout time.Duration) error {
+	if timeout < 0 {
+		return &InvalidArgument{Message: fmt.Sprintf("'%s' - invalid timeout. %s", path, timeout), Method: method, Path: path}
+	}
+	if err := rt.Register(method, path, name); err != nil {
+		return err
+	}
+	rt.routes[method][path].timeout = timeout
+	return nil
+}
+
+// RegisterDefaults : 省略可能なパラメータの既定値付きでルートパスを登録する
+// defaults のキーはパラメータ名とする。既定値が指定されていないパラメータは、アクションの引数の型のゼロ値となる
+// 同じアクションで登録済みのルートパスの場合は、実行制限時間、メタデータを保持したまま既定値を設定する
+// ex) r.RegisterDefaults("GET", "/archive(/:year<int>(/:month<int>))", "Archive.Index", map[string]interface{}{"year": 2024})
+func (rt *RouteTable) RegisterDefaults(method, path, name string, defaults map[string]interface{}) error {
+	if err := rt.Register(method, path, name); err != nil {
+		return err
+	}
+	rt.routes[method][path].defaults = defaults
 	return nil
 }
 
 // Create : 登録されたルートパスを
 func (rt *RouteTable) Create() (Router, error) {
+	return rt.create(false)
+}
+
+// CreateDry : コントローラを検証せずにルーティングを生成する
+// アクションは Generator へコントローラを nil として生成する。既定の Generator の場合、アクションは実行できないが、
+// Caller で該当するアクション名と引数を確認可能
+func (rt *RouteTable) CreateDry() (Router, error) {
+	return rt.create(true)
+}
+
+// create : ルーティングを生成する。dry が true の場合は、コントローラを参照しない
+func (rt *RouteTable) create(dry bool) (Router, error) {
 	var result = make(Router)
+	if rt.Generator == nil {
+		return nil, &InvalidArgument{Message: "action generator is nil pointer"}
+	}
 
 	// ex) map[GET]map[/:id]*Route を GET, map[/:id]*Route として処理する
 	for method, routes := range rt.routes {
 		// ルーティング構造体を生成
 		routing := &Routing{
-			access: new(trie.Trie),
-			regexp: make(map[*regexp.Regexp]interface{}),
+			access:  new(trie.Tree[Result]),
+			regexp:  make(map[*regexp.Regexp]*pattern),
+			options: rt.Options,
 		}
 		result[method] = routing
 		// map[/:id]*Route を /:id, *Route として処理する
 		for path, route := range routes {
+			// 別名の場合は、実行するルートパスの内容で登録する
+			if route.alias != "" {
+				target, err := rt.resolveAlias(method, path, route)
+				if err != nil {
+					return nil, err
+				}
+				resolved := *target
+				resolved.prior = route.prior
+				route = &resolved
+			}
+			// リダイレクトの場合は、コントローラを参照せずに登録する
+			if route.redirect != "" {
+				action, err := rt.redirectAction(path, route)
+				if err != nil {
+					return nil, withRoute(err, method, path)
+				}
+				if err := rt.scope(route.ctlname).addPath(routing, method, path, route, action, nil); err != nil {
+					return nil, withRoute(err, method, path)
+				}
+				continue
+			}
+			// コントローラを参照しない場合は、コントローラを nil としてアクションを生成する
+			if dry {
+				action := rt.Generator.Action(route.ctlname, route.actname, nil)
+				setMeta(action, route.meta)
+				if err := rt.scope(route.ctlname).addPath(routing, method, path, route, action, nil); err != nil {
+					return nil, withRoute(err, method, path)
+				}
+				continue
+			}
 			// コントローラオブジェクトを取得する
-			controller, ok := rt.classes[route.ctlname]
-			if !ok {
-				return nil, fmt.Errorf("'%s' - controller not registered", route.ctlname)
+			key, controller, err := rt.lookupClass(route.ctlname)
+			if err != nil {
+				return nil, withRoute(err, method, path)
 			}
 			// アクションオブジェクトを生成する
-			if rt.Generator == nil {
-				return nil, fmt.Errorf("action generator is nil pointer")
-			}
 			action := rt.Generator.Action(route.ctlname, route.actname, controller)
+			setMeta(action, route.meta)
+			// 実行制限時間を設定可能なアクションの場合、制限時間を設定する
+			if setter, ok := action.(interface{ SetTimeout(time.Duration) }); ok {
+				timeout := route.timeout
+				if timeout == 0 {
+					timeout = rt.Timeout
+				}
+				setter.SetTimeout(timeout)
+			}
 			// アクションオブジェクトが正しい設定値であるか検証する
-			if _, err := action.Get(); err != nil {
-				return nil, err
+			caller, err := action.Get()
+			if err != nil {
+				return nil, withRoute(err, method, path)
 			}
-			// パスを設定する
-			if !route.prior {
-				// 優先度が低い場合、パス内の:<name>を正規表現文字列に置き換える
-				var p = path
-				for name, reg := range rt.regex {
-					p = strings.Replace(p, name, reg, -1)
+			// コントローラ生成関数が登録されている場合は、アクションへ設定する
+			// コントローラ、サービスは名前空間に該当する RouteTable のものを使用する
+			scope := rt.scope(route.ctlname)
+			if factory, ok := scope.factories[key]; ok {
+				if setter, ok := action.(interface{ SetFactory(Factory) }); ok {
+					setter.SetFactory(factory)
 				}
-				// 正しく正規表現が置き換えられたかチェックする
-				if strings.Count(p, ":") != 0 {
-					return nil, fmt.Errorf("regexp in '%s' path is not registered", path)
+			}
+			// コントローラが要求するサービスが登録されているか検証し、アクションへ設定する
+			if scope.Container != nil {
+				if err := scope.Container.Check(route.ctlname, reflect.TypeOf(controller)); err != nil {
+					return nil, withRoute(err, method, path)
 				}
-				// 正規表現を使用したアクセスパスを生成する
-				regexp, err := regexp.Compile("^" + p + "$")
-				if err != nil {
-					return nil, fmt.Errorf("'%s.%s' - %s", route.ctlname, route.actname, err)
+				if setter, ok := action.(interface{ SetContainer(*Container) }); ok {
+					setter.SetContainer(scope.Container)
 				}
-				routing.regexp[regexp] = action
-			} else {
-				// 優先度が高い場合、固定パスを登録する
-				routing.access.Add(path, action)
+			}
+			// 省略可能なパラメータの既定値を求めるため、アクションの引数の型情報を取得する
+			var fn reflect.Type
+			if method := caller.MethodByName(route.actname); method.IsValid() {
+				fn = method.Type()
+			}
+			// パスを設定する。正規表現は名前空間に該当する RouteTable のものを使用する
+			if err := scope.addPath(routing, method, path, route, action, fn); err != nil {
+				return nil, withRoute(err, method, path)
 			}
 		}
 	}
 	return result, nil
 }
 
+// addPath : パスとアクションをルーティング構造体へ登録する。fn はアクションの型情報
+func (rt *RouteTable) addPath(routing *Routing, method, path string, route *Route, action Result, fn reflect.Type) error {
+	// 優先度が高い場合、固定パスを登録する
+	if route.prior {
+		if err := routing.access.Add(rt.Options.key(path), action); err != nil {
+			return &RouteConflict{
+				Message: fmt.Sprintf("'[%s]: %s' - %s", method, path, err),
+				Method:  method,
+				Paths:   []string{path},
+			}
+		}
+		return nil
+	}
+	// 正規化後のパスと一致しない場合は、照合できないためエラーとする
+	if err := rt.Options.normalized(method, path); err != nil {
+		return err
+	}
+	// 優先度が低い場合、パス内の:<name>、:<name><type>を正規表現文字列に置き換える
+	c, err := rt.compile(path)
+	if err != nil {
+		return err
+	}
+	// 正規表現を使用したアクセスパスを生成する
+	regexp, err := rt.pathRegexp(c)
+	if err != nil {
+		return &InvalidPath{
+			Message:    fmt.Sprintf("'%s.%s' - %s", route.ctlname, route.actname, err),
+			Controller: route.ctlname,
+			Err:        err,
+		}
+	}
+	pat, err := newPattern(path, c, action, fn, route.defaults)
+	if err != nil {
+		return err
+	}
+	routing.regexp[regexp] = pat
+	return nil
+}
+
+// pathRegexp : 変換したパスから、パス全体に一致する正規表現を生成する
+func (rt *RouteTable) pathRegexp(c *compiled) (*regexp.Regexp, error) {
+	p := c.pattern
+	if rt.Options.CaseInsensitive {
+		p = "(?i)" + p
+	}
+	return regexp.Compile("^" + p + "$")
+}
+
 // Routing : ルーティングパス構造体
 type Routing struct {
-	access *trie.Trie                     // 固定パス
-	regexp map[*regexp.Regexp]interface{} // 正規表現形式のパス
+	access  *trie.Tree[Result]          // 固定パス
+	regexp  map[*regexp.Regexp]*pattern // 正規表現形式のパス
+	options Options                     // パスの照合方法
+}
+
+// match : 指定されたパスに該当するアクションと、正規表現で抜き出した引数を返却する
+// path はパーセントエンコードされたパス (url.URL.EscapedPath()) であること
+func (routing *Routing) match(path string) (Result, []reflect.Value, bool) {
+	var args []reflect.Value
+
+	// セグメントの区切りを維持したままデコードする。不正なエスケープの場合はマッチしない
+	// 引数をエンコードされたまま抜き出す場合は、デコード後の各位置に対応する path の位置を求める
+	decoded, offsets, err := unescapeOffsets(path, routing.options.RawCaptures)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	// 指定されたパスを固定パスとしてアクションを取得する
+	if action, ok := routing.access.Lookup(routing.options.key(decoded)); ok {
+		return action, args, true
+	}
+
+	// 固定パスとして取得できない場合、正規表現形式のパスとしてアクションを取得する
+	// 照合はデコードしたパスで行い、RawCaptures の場合は抜き出した値のみエンコードされたパスから取得する
+next:
+	for reg, obj := range routing.regexp {
+		// マッチしない場合は、次の正規表現へ
+		if !reg.MatchString(decoded) {
+			continue
+		}
+		// マッチした場合は、正規表現で引っかかった文字列のみを抜き出す
+		args = nil
+		locs := reg.FindStringSubmatchIndex(decoded)
+		// 抜き出した文字列をデコード、型変換し、配列へ格納する
+		for i := 1; i < len(locs)/2; i++ {
+			// 省略された場合は、既定値を格納する
+			if locs[2*i] < 0 {
+				args = append(args, obj.value(i-1))
+				continue
+			}
+			captured := decoded[locs[2*i]:locs[2*i+1]]
+			if offsets != nil {
+				captured = path[offsets[locs[2*i]]:offsets[locs[2*i+1]]]
+			}
+			v, err := routing.options.capture(captured)
+			if err != nil {
+				return nil, nil, false
+			}
+			// 型制約を満たさない場合は、次の正規表現へ
+			value, err := obj.convert(i-1, v)
+			if err != nil {
+				continue next
+			}
+			args = append(args, reflect.ValueOf(value))
+		}
+		return obj.action, args, true
+	}
+	return nil, nil, false
 }
 
 // Router : 各ルーティングパスを、メソッド(GET/POST)単位で取り扱うマップ
 type Router map[string]*Routing
 
 // Caller : 関数実行用オブジェクトを返却する
+// path にはパーセントエンコードされたパス (url.URL.EscapedPath()) を指定する。
+// 正規表現で抜き出した値は、Options.RawCaptures が true の場合を除きデコードして返却する
 func (r Router) Caller(method, path string) (Result, []reflect.Value, error) {
-	var args []reflect.Value
-
 	// 指定されたメソッド名に該当するルーティング構造体を取得する
 	routing, ok := r[method]
 	if !ok {
-		return nil, nil, &NotRoutes{
+		err := &NotRoutes{
 			Message: fmt.Sprintf("'[%s]: %s' - not found", method, path),
 			Method:  method,
 			Path:    path,
 		}
+		// オプションはすべてのメソッドで共通のため、他のメソッドのオプションを使用する
+		for _, other := range r {
+			if other.options.Suggest {
+				err.Suggestions = r.suggest(method, path, other.options)
+				err.Message += suggestMessage(err.Suggestions)
+			}
+			break
+		}
+		return nil, nil, err
 	}
 
-	// 指定されたパスを固定パスとしてアクションを取得する
-	i := routing.access.Get(path)
-	// 固定パスとして取得できない場合、正規表現形式のパスとしてアクションを取得する
-	if i == nil {
-		for reg, obj := range routing.regexp {
-			// マッチしない場合は、次の正規表現へ
-			if !reg.MatchString(path) {
-				continue
+	// オプションに従いパスを正規化し、アクションを取得する
+	normalized := routing.options.normalize(path)
+	if action, args, ok := routing.match(normalized); ok {
+		return redirectResult(action, args)
+	}
+
+	// 末尾のスラッシュの有無のみが異なるパスを検索する
+	var redirect string
+	if toggled := toggleSlash(normalized); toggled != "" {
+		switch routing.options.TrailingSlash {
+		case TrailingSlashIgnore:
+			if action, args, ok := routing.match(toggled); ok {
+				return redirectResult(action, args)
 			}
-			// マッチした場合は、正規表現で引っかかった文字列のみを抜き出す
-			strs := reg.FindAllStringSubmatch(path, -1)
-			if len(strs) > 0 {
-				// 抜き出した文字列を配列へ格納する
-				for i := 1; i < len(strs[0]); i++ {
-					args = append(args, reflect.ValueOf(strs[0][i]))
-				}
+		case TrailingSlashRedirect:
+			if _, _, ok := routing.match(toggled); ok {
+				redirect = toggled
 			}
-			i = obj
-			break
 		}
 	}
 
 	// アクションの取得失敗の場合、nil を返却する
-	if i == nil {
-		return nil, nil, &NotRoutes{
-			Message: fmt.Sprintf("'[%s]: %s' - not found", method, path),
-			Method:  method,
-			Path:    path,
-		}
+	// パーセントエンコードが不正な場合は、そのエラーを格納する
+	_, cause := unescapePath(normalized)
+	err := &NotRoutes{
+		Message:  fmt.Sprintf("'[%s]: %s' - not found", method, path),
+		Method:   method,
+		Path:     path,
+		Redirect: redirect,
+		Err:      cause,
 	}
-
-	// interface{} を Action 構造体へ変換する
-	action, ok := i.(Result)
-	if !ok {
-		return nil, nil, fmt.Errorf("action struct is invalid")
+	// 候補となるルートを設定する
+	if routing.options.Suggest {
+		err.Suggestions = r.suggest(method, path, routing.options)
+		err.Message += suggestMessage(err.Suggestions)
 	}
-
-	return action, args, nil
+	return nil, nil, err
 }
 
 // Generator : 生成するアクションオブジェクトのジェネレータ
@@ -369,16 +767,24 @@ type Generator interface {
 type Result interface {
 	Get() (reflect.Value, error)
 	Call([]reflect.Value, ...string) ([]reflect.Value, error)
+	CallContext(context.Context, []reflect.Value, ...string) ([]reflect.Value, error)
 	Name() (string, string)
 	Valid(reflect.Value, []reflect.Value, ...string) (reflect.Value, error)
 	Callname(reflect.Value, string, []reflect.Value, ...string) ([]reflect.Value, error)
 }
 
+// Factory : アクセス時にコントローラのインスタンスを生成する関数。構造体へのポインタを返却すること
+type Factory func() (reflect.Value, error)
+
 // Action : アクション登録用構造体
 type Action struct {
 	Ctlname    string
 	Actname    string
 	Controller interface{}
+	Timeout    time.Duration // アクションの実行制限時間。0 の場合は無制限
+	Container  *Container    // インスタンス生成時に注入するサービス
+	Factory    Factory       // インスタンス生成関数。nil の場合はゼロ値のインスタンスを生成する
+	Meta       *Meta         // ルートパスのメタデータ
 }
 
 // Get : アクションを実行するCallerを取得する
@@ -389,20 +795,63 @@ func (action *Action) Get() (reflect.Value, error) {
 		return reflect.Value{}, fmt.Errorf("'%s.%s' - is nil", action.Ctlname, action.Actname)
 	}
 
+	// 構造体へのポインタで登録されている場合は、構造体の型を取得する
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
 	// 登録済みのコントローラの型が構造体型かチェックする
 	if typ.Kind() != reflect.Struct {
 		return reflect.Value{}, fmt.Errorf("'%s.%s' - not struct type", action.Ctlname, action.Actname)
 	}
 
-	caller := reflect.New(typ)
+	// インスタンスを生成する
+	caller, err := action.instance(reflect.New(typ))
+	if err != nil {
+		return reflect.Value{}, err
+	}
 	// コールする関数情報が不正ではないかチェックする
 	if caller.MethodByName(action.Actname).IsValid() == false {
-		return reflect.Value{}, fmt.Errorf("'%s.%s' - function undefined", action.Ctlname, action.Actname)
+		return reflect.Value{}, &ActionUndefined{
+			Message:    fmt.Sprintf("'%s.%s' - function undefined", action.Ctlname, action.Actname),
+			Controller: action.Ctlname,
+			Action:     action.Actname,
+		}
 	}
 
 	return caller, nil
 }
 
+// Instance : コントローラのインスタンスを生成し、サービスを注入して返却する。Generate で出力したソースコードから使用する
+// インスタンス生成関数が設定されていない場合は、c をインスタンスとする
+func (action *Action) Instance(c interface{}) (interface{}, error) {
+	if action.Factory == nil && action.Container == nil {
+		return c, nil
+	}
+	caller, err := action.instance(reflect.ValueOf(c))
+	if err != nil {
+		return nil, err
+	}
+	return caller.Interface(), nil
+}
+
+// instance : インスタンス生成関数が設定されている場合は生成関数から、それ以外は caller をインスタンスとし、
+// サービスが登録されている場合は、インスタンスへ注入する
+func (action *Action) instance(caller reflect.Value) (reflect.Value, error) {
+	if action.Factory != nil {
+		v, err := action.Factory()
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("'%s.%s' - %w", action.Ctlname, action.Actname, err)
+		}
+		caller = v
+	}
+	if action.Container != nil {
+		if err := action.Container.Inject(action.Ctlname, caller); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	return caller, nil
+}
+
 // Valid : 与えられた引数の数、型、復帰値の数、型がコールするメソッドと一致している場合、メソッド情報を返却する
 func (action *Action) Valid(caller reflect.Value, args []reflect.Value, ret ...string) (reflect.Value, error) {
 	// メソッド情報を取得
@@ -446,12 +895,13 @@ func (action *Action) valid(fn reflect.Value, actname string, args []reflect.Val
 		}
 
 		var errflag = true
+		var cause error
 		if typ.In(i).Kind() == reflect.Interface {
 			// コールする関数の引数の型情報がinterfaceの場合
 			func() {
 				defer func() {
 					if err := recover(); err != nil {
-						errflag = true
+						errflag, cause = true, fmt.Errorf("%v", err)
 					}
 				}()
 				errflag = false
@@ -462,7 +912,7 @@ func (action *Action) valid(fn reflect.Value, actname string, args []reflect.Val
 			func() {
 				defer func() {
 					if err := recover(); err != nil {
-						errflag = true
+						errflag, cause = true, fmt.Errorf("%v", err)
 					}
 				}()
 				errflag = false
@@ -483,6 +933,7 @@ func (action *Action) valid(fn reflect.Value, actname string, args []reflect.Val
 				args[i].Type().Name(), typ.In(i).Name(), action.Ctlname, actname),
 			Have: fmt.Sprintf("(%s)", strings.Join(have, ", ")),
 			Want: fmt.Sprintf("(%s)", strings.Join(want, ", ")),
+			Err:  cause,
 		}
 	}
 
@@ -539,18 +990,134 @@ func (action *Action) Callname(elem reflect.Value, methodname string, args []ref
 }
 
 // Call : 関数をコールする
+// context.Background() を ctx として CallContext を実行する。実行制限時間の有無によらず、
+// アクションの第1引数が context.Context の場合は ctx が先頭の引数として渡される
 func (action *Action) Call(args []reflect.Value, ret ...string) ([]reflect.Value, error) {
+	return action.CallContext(context.Background(), args, ret...)
+}
+
+// contextType : context.Context の型情報
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// CallContext : ctx を伴って関数をコールする
+// アクションの第1引数が context.Context の場合は、ctx を先頭の引数として渡す。
+// 呼び出し前に ctx がキャンセル済み、またはデッドラインを超過している場合は ctx.Err() を返却する。
+// 実行制限時間が設定されている場合は、制限時間付きの ctx を渡す
+func (action *Action) CallContext(ctx context.Context, args []reflect.Value, ret ...string) ([]reflect.Value, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// 既にキャンセルされている場合は、アクションを実行しない
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// アクション情報を取得
 	caller, err := action.Get()
 	if err != nil {
 		return nil, err
 	}
 
+	// 実行制限時間が設定されている場合は、制限時間付きの ctx を生成する
+	if action.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, action.Timeout)
+		defer cancel()
+	}
+
+	// 第1引数が context.Context の場合は、ctx を引数の先頭に挿入する
+	if fn := caller.MethodByName(action.Actname); fn.IsValid() {
+		if typ := fn.Type(); typ.NumIn() > 0 && typ.In(0) == contextType {
+			args = append([]reflect.Value{reflect.ValueOf(ctx)}, args...)
+		}
+	}
+
 	fn, err := action.Valid(caller, args, ret...)
 	if err != nil {
 		return nil, err
 	}
-	return fn.Call(args), nil
+	// 実行直前に再度キャンセルされていないかチェックする
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	// 実行制限時間が設定されていない場合は、そのままコールする
+	if action.Timeout <= 0 {
+		return fn.Call(args), nil
+	}
+	return action.CallTimeout(ctx, func() []reflect.Value { return fn.Call(args) })
+}
+
+// CallTimeout : 制限時間付きの ctx で、アクションを呼び出す関数 fn を実行する。Generate で出力したソースコードからも使用する
+// 制限時間を超過した場合、アクションの終了を待たずに ActionTimeout を返却する。
+// アクションを実行する goroutine は強制終了できず、アクションが終了するまで残り続けるため、
+// 時間のかかるアクションは第1引数に context.Context を受け取り、ctx.Done() を監視して処理を打ち切ること。
+// アクション内で panic が発生した場合は、スタックトレースを格納した *ActionPanic で呼び出し元へ panic を伝搬させる
+func (action *Action) CallTimeout(ctx context.Context, fn func() []reflect.Value) ([]reflect.Value, error) {
+	type result struct {
+		out   []reflect.Value
+		panic *ActionPanic
+	}
+	done := make(chan result, 1)
+	go func() {
+		var res result
+		defer func() {
+			if v := recover(); v != nil {
+				res.panic = &ActionPanic{
+					Message: fmt.Sprintf("'%s.%s' - panic: %v", action.Ctlname, action.Actname, v),
+					Ctlname: action.Ctlname,
+					Actname: action.Actname,
+					Value:   v,
+					Stack:   debug.Stack(),
+				}
+			}
+			done <- res
+		}()
+		res.out = fn()
+	}()
+
+	select {
+	case res := <-done:
+		// アクション内で発生した panic は呼び出し元へ伝搬させる
+		if res.panic != nil {
+			panic(res.panic)
+		}
+		return res.out, nil
+	case <-ctx.Done():
+		if ctx.Err() != context.DeadlineExceeded {
+			return nil, ctx.Err()
+		}
+		return nil, &ActionTimeout{
+			Message: fmt.Sprintf("'%s.%s' - timeout. %s elapsed", action.Ctlname, action.Actname, action.Timeout),
+			Ctlname: action.Ctlname,
+			Actname: action.Actname,
+			Timeout: action.Timeout,
+		}
+	}
+}
+
+// SetFactory : インスタンス生成関数を設定する
+func (action *Action) SetFactory(factory Factory) {
+	action.Factory = factory
+}
+
+// SetContainer : インスタンス生成時に注入するサービスを設定する
+func (action *Action) SetContainer(container *Container) {
+	action.Container = container
+}
+
+// SetTimeout : アクションの実行制限時間を設定する
+func (action *Action) SetTimeout(timeout time.Duration) {
+	action.Timeout = timeout
+}
+
+// SetMeta : ルートパスのメタデータを設定する
+func (action *Action) SetMeta(meta *Meta) {
+	action.Meta = meta
+}
+
+// Metadata : ルートパスのメタデータを返却する
+func (action *Action) Metadata() *Meta {
+	return action.Meta
 }
 
 // Name : コントローラ名とアクション名を返却する
@@ -682,15 +1249,67 @@ func HasName(elem reflect.Value, ctlname, actname string) bool {
 
 // NotRoutes : 指定したパス、またはメソッドが存在しない場合のエラー型
 type NotRoutes struct {
-	Message string
-	Path    string
-	Method  string
+	Message     string
+	Path        string
+	Method      string
+	Redirect    string       // Options.TrailingSlash が TrailingSlashRedirect の場合の、リダイレクト先のパス
+	Err         error        // パスのパーセントエンコードが不正な場合のエラー
+	Suggestions []Suggestion // Options.Suggest が true の場合の、候補となるルート
 }
 
 func (err *NotRoutes) Error() string {
 	return err.Message
 }
 
+// Unwrap : パスのパーセントエンコードが不正な場合、そのエラーを返却する
+func (err *NotRoutes) Unwrap() error {
+	return err.Err
+}
+
+// Is : ErrNotFound と比較した場合 true を返却する
+func (err *NotRoutes) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// ActionTimeout : アクションの実行が制限時間を超過した場合のエラー型
+type ActionTimeout struct {
+	Message string
+	Ctlname string
+	Actname string
+	Timeout time.Duration
+}
+
+func (err *ActionTimeout) Error() string {
+	return err.Message
+}
+
+// Unwrap : errors.Is(err, context.DeadlineExceeded) で判定可能とする
+func (err *ActionTimeout) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// ActionPanic : 制限時間付きで実行したアクション内で panic が発生した場合に、呼び出し元で panic する値
+// アクションは別の goroutine で実行されるため、Stack に panic が発生した goroutine のスタックトレースを格納する
+type ActionPanic struct {
+	Message string
+	Ctlname string
+	Actname string
+	Value   interface{} // アクション内で panic に渡された値
+	Stack   []byte      // panic が発生した goroutine のスタックトレース
+}
+
+func (err *ActionPanic) Error() string {
+	return fmt.Sprintf("%s\n\n%s", err.Message, err.Stack)
+}
+
+// Unwrap : panic に渡された値が error の場合、その値を返却する
+func (err *ActionPanic) Unwrap() error {
+	if v, ok := err.Value.(error); ok {
+		return v
+	}
+	return nil
+}
+
 // NotEnoughArgs : コールするメソッドの引数の数が一致しない場合のエラー型
 type NotEnoughArgs struct {
 	Message string
@@ -702,17 +1321,33 @@ func (err *NotEnoughArgs) Error() string {
 	return err.Message
 }
 
+// Is : ErrInvalidArgs と比較した場合 true を返却する
+func (err *NotEnoughArgs) Is(target error) bool {
+	return target == ErrInvalidArgs
+}
+
 // IllegalArgs : コールするメソッドの引数の型が一致しない場合のエラー型
 type IllegalArgs struct {
 	Message string
 	Have    string
 	Want    string
+	Err     error // 型の変換に失敗した場合の原因
 }
 
 func (err *IllegalArgs) Error() string {
 	return err.Message
 }
 
+// Unwrap : 型の変換に失敗した場合、その原因を返却する
+func (err *IllegalArgs) Unwrap() error {
+	return err.Err
+}
+
+// Is : ErrInvalidArgs と比較した場合 true を返却する
+func (err *IllegalArgs) Is(target error) bool {
+	return target == ErrInvalidArgs
+}
+
 // NotEnoughRets : コールするメソッドの復帰値の数が一致しない場合のエラー型
 type NotEnoughRets struct {
 	Message string
@@ -724,6 +1359,11 @@ func (err *NotEnoughRets) Error() string {
 	return err.Message
 }
 
+// Is : ErrInvalidRets と比較した場合 true を返却する
+func (err *NotEnoughRets) Is(target error) bool {
+	return target == ErrInvalidRets
+}
+
 // IllegalRets : コールするメソッドの復帰値の型が一致しない場合のエラー型
 type IllegalRets struct {
 	Message string
@@ -735,6 +1375,11 @@ func (err *IllegalRets) Error() string {
 	return err.Message
 }
 
+// Is : ErrInvalidRets と比較した場合 true を返却する
+func (err *IllegalRets) Is(target error) bool {
+	return target == ErrInvalidRets
+}
+
 // InvalidError : SetStructに渡した引数が不正な場合のエラー型
 type InvalidError struct {
 	Message string
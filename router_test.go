@@ -1,9 +1,15 @@
 package router
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/ochipin/router/internal/sample"
 )
 
 type MixinStruct struct{ Ok bool }
@@ -22,6 +28,17 @@ func (s *Sample) World()                   { fmt.Println("OK!! World") }
 func (s *Sample) TheTest(a string)         { fmt.Println("The Test") }
 func (s *Sample) Sample(a ResultType)      {}
 func (s *Sample) Convert(a String) String  { return "" }
+func (s *Sample) Sleep(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Second):
+		return nil
+	}
+}
+func (s *Sample) Wait(ctx context.Context, a string) string {
+	return fmt.Sprintf("Wait %s %v", a, ctx.Err())
+}
 
 type TestString string
 type String = TestString
@@ -683,3 +700,316 @@ func Test__ROUTER_TABLELIST(t *testing.T) {
 		t.Fatal("TableList: ERROR")
 	}
 }
+
+func Test__ROUTER_CALLCONTEXT(t *testing.T) {
+	r := New()
+	r.AddClass(Sample{})
+	r.AddRegexp("n", "([0-9]+)")
+	r.Register("GET", "/wait/:n", "Sample.Wait")
+	r.Register("GET", "/:n/:n", "Sample.Hello")
+	router, err := r.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 第1引数が context.Context の場合、ctx が先頭に挿入される
+	caller, args, err := router.Caller("GET", "/wait/10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := caller.CallContext(context.Background(), args, "string")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result[0].String() != "Wait 10 <nil>" {
+		t.Fatal("CallContext: Error", result[0].String())
+	}
+	// Call の場合も、実行制限時間の有無によらず ctx が先頭に挿入される
+	if result, err := caller.Call(args, "string"); err != nil || result[0].String() != "Wait 10 <nil>" {
+		t.Fatal("Call: Error", result, err)
+	}
+
+	// キャンセル済みの場合、アクションは実行されない
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := caller.CallContext(ctx, args); err != context.Canceled {
+		t.Fatal("CallContext: Error", err)
+	}
+
+	// 第1引数が context.Context ではない場合、ctx は挿入されない
+	caller, args, err = router.Caller("GET", "/1/2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err = caller.CallContext(context.Background(), args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result[0].String() != "Hello 1 2" {
+		t.Fatal("CallContext: Error", result[0].String())
+	}
+}
+
+func Test__ROUTER_TIMEOUT(t *testing.T) {
+	r := New()
+	r.AddClass(Sample{})
+	r.AddRegexp("n", "([0-9]+)")
+	// 負の制限時間を指定した場合はエラーとなる
+	if err := r.RegisterTimeout("GET", "/sleep", "Sample.Sleep", -1); err == nil {
+		t.Fatal("RegisterTimeout: Error")
+	}
+	if err := r.RegisterTimeout("GET", "/sleep", "Sample.Sleep", 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	r.Register("GET", "/wait/:n", "Sample.Wait")
+	r.Timeout = time.Second
+	router, err := r.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 制限時間を超過した場合、ActionTimeout が返却される
+	caller, args, err := router.Caller("GET", "/sleep")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = caller.CallContext(context.Background(), args)
+	if v, ok := err.(*ActionTimeout); !ok {
+		t.Fatal("CallContext: Error", err)
+	} else if v.Timeout != 10*time.Millisecond || v.Actname != "Sleep" {
+		t.Fatal("CallContext: Error", v)
+	}
+	// Call でも制限時間が適用される
+	if _, err := caller.Call(args); !errors.As(err, new(*ActionTimeout)) {
+		t.Fatal("Call: Error", err)
+	}
+
+	// RouteTable.Timeout が既定値として適用され、制限時間内に完了した場合は結果が返却される
+	caller, args, err = router.Caller("GET", "/wait/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := caller.CallContext(context.Background(), args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result[0].String() != "Wait 1 <nil>" {
+		t.Fatal("CallContext: Error", result[0].String())
+	}
+
+	// マウントした RouteTable の Timeout は、グループ単位の制限時間として適用される
+	sub := New()
+	sub.AddClass(Sample{})
+	sub.Register("GET", "/sleep", "Sample.Sleep")
+	sub.Timeout = 10 * time.Millisecond
+	r.Mount("slow", "/slow", sub)
+	router, err = r.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	caller, args, _ = router.Caller("GET", "/slow/sleep")
+	var timeout *ActionTimeout
+	if _, err := caller.Call(args); !errors.As(err, &timeout) || timeout.Timeout != 10*time.Millisecond {
+		t.Fatal("Call: Error", err)
+	}
+}
+
+type Panics struct{}
+
+func (p *Panics) Boom(ctx context.Context) { panic("boom") }
+
+func Test__ROUTER_TIMEOUT_PANIC(t *testing.T) {
+	r := New()
+	r.AddClass(Panics{})
+	r.RegisterTimeout("GET", "/boom", "Panics.Boom", time.Second)
+	router, err := r.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	caller, args, _ := router.Caller("GET", "/boom")
+
+	// panic は、発生した goroutine のスタックトレースと共に呼び出し元へ伝搬する
+	defer func() {
+		v, ok := recover().(*ActionPanic)
+		if !ok || v.Value != "boom" || !strings.Contains(string(v.Stack), "Boom") {
+			t.Fatal("Call: Error", v)
+		}
+	}()
+	caller.Call(args)
+	t.Fatal("Call: Error")
+}
+
+type Configured struct {
+	Greeting string
+	Count    *int
+}
+
+func (c *Configured) Hello(name string) string { return c.Greeting + " " + name }
+
+func Test__ROUTER_FACTORY(t *testing.T) {
+	r := New()
+	// 関数ではない値、不正な形式の関数を登録した場合はエラーとなる
+	if err := r.AddConstructor(Configured{}); err == nil {
+		t.Fatal("AddConstructor: Error")
+	}
+	if err := r.AddConstructor(func() int { return 0 }); err == nil {
+		t.Fatal("AddConstructor: Error")
+	}
+	if err := r.AddConstructor(func(int) *Configured { return nil }); err == nil {
+		t.Fatal("AddConstructor: Error")
+	}
+
+	// 生成関数を登録する
+	var n int
+	if err := r.AddConstructor(func() *Configured {
+		n++
+		return &Configured{Greeting: "Hello"}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if r.BoolClass(Configured{}) != true {
+		t.Fatal("BoolClass: Error")
+	}
+	r.AddRegexp("name", "([a-z]+)")
+	r.Register("GET", "/:name", "Configured.Hello")
+	router, err := r.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Create 時点では生成関数は実行されない
+	if n != 0 {
+		t.Fatal("Create: Error", n)
+	}
+	caller, args, err := router.Caller("GET", "/world")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := caller.Call(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result[0].String() != "Hello world" || n != 1 {
+		t.Fatal("Call: Error", result[0].String(), n)
+	}
+
+	// プロトタイプを登録した場合、フィールドの値がコピーされる
+	var count = 10
+	r.AddPrototype(Configured{Greeting: "Hi", Count: &count})
+	router, _ = r.Create()
+	caller, _, _ = router.Caller("GET", "/world")
+	c1, _ := caller.Get()
+	c2, _ := caller.Get()
+	if c1.Pointer() == c2.Pointer() {
+		t.Fatal("AddPrototype: Error")
+	}
+	if v := c1.Interface().(*Configured); v.Greeting != "Hi" || v.Count != &count {
+		t.Fatal("AddPrototype: Error")
+	}
+	// インスタンスへの変更はプロトタイプへ影響しない
+	c1.Interface().(*Configured).Greeting = "Bye"
+	if v := c2.Interface().(*Configured); v.Greeting != "Hi" {
+		t.Fatal("AddPrototype: Error")
+	}
+
+	// 生成関数がエラーを返却した場合、Call はそのエラーを内包するエラーとなる
+	errNotConfigured := errors.New("not configured")
+	r.AddConstructor(func() (Configured, error) {
+		return Configured{}, errNotConfigured
+	})
+	router, _ = r.Create()
+	caller, args, _ = router.Caller("GET", "/world")
+	if _, err := caller.Call(args); !errors.Is(err, errNotConfigured) {
+		t.Fatal("Call: Error", err)
+	}
+
+	// AddClass で再登録した場合、ゼロ値のインスタンスが生成される
+	r.AddClass(Configured{Greeting: "Ignored"})
+	router, _ = r.Create()
+	caller, args, _ = router.Caller("GET", "/world")
+	if result, _ := caller.Call(args); result[0].String() != " world" {
+		t.Fatal("Call: Error", result[0].String())
+	}
+}
+
+type Pointer struct{ Name string }
+
+func (p *Pointer) Index() string { return "Pointer:" + p.Name }
+
+func Test__ROUTER_POINTER(t *testing.T) {
+	r := New()
+	// 構造体へのポインタを登録可能
+	if err := r.AddClass(&Pointer{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AddClass(new(int)); err == nil {
+		t.Fatal("AddClass: Error")
+	}
+	if r.BoolClass(Pointer{}) != true || r.BoolClass(&Pointer{}) != true {
+		t.Fatal("BoolClass: Error")
+	}
+	if err := r.AddPrototype((*Pointer)(nil)); err == nil {
+		t.Fatal("AddPrototype: Error")
+	}
+	r.Register("GET", "/", "Pointer.Index")
+	router, err := r.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	caller, args, _ := router.Caller("GET", "/")
+	if result, err := caller.Call(args); err != nil {
+		t.Fatal(err)
+	} else if result[0].String() != "Pointer:" {
+		t.Fatal("Call: Error", result[0].String())
+	}
+
+	// ポインタで登録したプロトタイプは、指し示す構造体がコピーされる
+	r.AddPrototype(&Pointer{Name: "proto"})
+	router, _ = r.Create()
+	caller, args, _ = router.Caller("GET", "/")
+	if result, _ := caller.Call(args); result[0].String() != "Pointer:proto" {
+		t.Fatal("Call: Error", result[0].String())
+	}
+}
+
+func Test__ROUTER_QUALIFIED(t *testing.T) {
+	r := New()
+	// 異なるパッケージの同名コントローラを登録しても衝突しない
+	r.AddClass(Sample{})
+	r.AddPrototype(sample.Sample{Name: "other"})
+	if r.BoolClass(Sample{}) != true || r.BoolClass(sample.Sample{}) != true {
+		t.Fatal("BoolClass: Error")
+	}
+
+	// 構造体名のみでは、どちらのコントローラか判別できないためエラーとなる
+	r.Register("GET", "/", "Sample.Index")
+	if _, err := r.Create(); err == nil {
+		t.Fatal("Create: Error")
+	}
+
+	// パッケージ名、またはパッケージパスを付与して登録する
+	r = New()
+	r.AddClass(Sample{})
+	r.AddPrototype(sample.Sample{Name: "other"})
+	if err := r.Register("GET", "/", "sample.Sample.Index"); err != nil {
+		t.Fatal(err)
+	}
+	r.Register("GET", "/world", "github.com/ochipin/router.Sample.World")
+	// アクション名が空の場合はエラーとなる
+	if err := r.Register("GET", "/", "Sample."); err == nil {
+		t.Fatal("Register: Error")
+	}
+	router, err := r.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	caller, args, _ := router.Caller("GET", "/")
+	if result, err := caller.Call(args); err != nil {
+		t.Fatal(err)
+	} else if result[0].String() != "sample.Sample:other" {
+		t.Fatal("Call: Error", result[0].String())
+	}
+	caller, _, _ = router.Caller("GET", "/world")
+	if ctlname, actname := caller.Name(); ctlname != "github.com/ochipin/router.Sample" || actname != "World" {
+		t.Fatal("Name: Error", ctlname, actname)
+	}
+}
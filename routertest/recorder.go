@@ -0,0 +1,141 @@
+package routertest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/ochipin/router"
+)
+
+// Invocation : Recorder が記録したアクションの呼び出し
+type Invocation struct {
+	Ctlname string
+	Actname string
+	Args    []interface{}
+}
+
+// Name : Controller.Action 形式の名前を返却する
+func (inv Invocation) Name() string {
+	return inv.Ctlname + "." + inv.Actname
+}
+
+// Recorder : コントローラの代わりにアクションの呼び出しを記録する Generator
+// コントローラを登録せずに、RouteTable.CreateDry で生成したルーティングを検証可能
+//
+//	rec := routertest.NewRecorder()
+//	rt.Generator = rec
+//	r, _ := rt.CreateDry()
+type Recorder struct {
+	mu      sync.Mutex
+	calls   []Invocation
+	returns map[string][]interface{}
+}
+
+// NewRecorder : Recorder を生成する
+func NewRecorder() *Recorder {
+	return &Recorder{returns: make(map[string][]interface{})}
+}
+
+// Action : 呼び出しを記録するアクションを生成する
+func (rec *Recorder) Action(ctlname, actname string, controller interface{}) router.Result {
+	return &recordAction{Action: router.Action{Ctlname: ctlname, Actname: actname, Controller: controller}, rec: rec}
+}
+
+// Return : name (Controller.Action) が呼び出された際の復帰値を設定する
+func (rec *Recorder) Return(name string, values ...interface{}) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.returns[name] = values
+}
+
+// Calls : 記録したアクションの呼び出しを、呼び出された順に返却する
+func (rec *Recorder) Calls() []Invocation {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append([]Invocation{}, rec.calls...)
+}
+
+// Reset : 記録したアクションの呼び出しを破棄する
+func (rec *Recorder) Reset() {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.calls = nil
+}
+
+// AssertCalled : 最後に呼び出されたアクションが name (Controller.Action) であり、引数が args と一致することを検証する
+func (rec *Recorder) AssertCalled(t testing.TB, name string, args ...interface{}) {
+	t.Helper()
+	calls := rec.Calls()
+	if len(calls) == 0 {
+		t.Errorf("want %s called, got no calls", name)
+		return
+	}
+	last := calls[len(calls)-1]
+	if last.Name() != name {
+		t.Errorf("want %s called, got %s", name, last.Name())
+	}
+	values := make([]reflect.Value, len(last.Args))
+	for i, arg := range last.Args {
+		values[i] = reflect.ValueOf(arg)
+	}
+	if !equalArgs(values, args) {
+		t.Errorf("%s - want args %s, got %s", name, formatArgs(args), formatArgs(last.Args))
+	}
+}
+
+// record : 呼び出しを記録し、設定された復帰値を返却する
+func (rec *Recorder) record(ctlname, actname string, args []reflect.Value) []reflect.Value {
+	inv := Invocation{Ctlname: ctlname, Actname: actname, Args: make([]interface{}, len(args))}
+	for i, arg := range args {
+		inv.Args[i] = arg.Interface()
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.calls = append(rec.calls, inv)
+	out := make([]reflect.Value, len(rec.returns[inv.Name()]))
+	for i, v := range rec.returns[inv.Name()] {
+		out[i] = reflect.ValueOf(v)
+	}
+	return out
+}
+
+// recordAction : 呼び出しを Recorder へ記録するアクション
+type recordAction struct {
+	router.Action
+	rec *Recorder
+}
+
+// Get : コントローラの代わりに Recorder を返却する
+func (action *recordAction) Get() (reflect.Value, error) {
+	return reflect.ValueOf(action.rec), nil
+}
+
+// Valid : 引数、復帰値を検証せずに、Recorder を返却する
+func (action *recordAction) Valid(caller reflect.Value, args []reflect.Value, ret ...string) (reflect.Value, error) {
+	return caller, nil
+}
+
+// Call : 呼び出しを記録する
+func (action *recordAction) Call(args []reflect.Value, ret ...string) ([]reflect.Value, error) {
+	return action.rec.record(action.Ctlname, action.Actname, args), nil
+}
+
+// CallContext : ctx がキャンセルされていない場合、呼び出しを記録する
+func (action *recordAction) CallContext(ctx context.Context, args []reflect.Value, ret ...string) ([]reflect.Value, error) {
+	if ctx != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return action.Call(args, ret...)
+}
+
+// Callname : 指定した名前のアクションとして、呼び出しを記録する
+func (action *recordAction) Callname(elem reflect.Value, methodname string, args []reflect.Value, ret ...string) ([]reflect.Value, error) {
+	if elem.Interface() != action.rec {
+		return nil, fmt.Errorf("'%s.%s' - not recorder", action.Ctlname, methodname)
+	}
+	return action.rec.record(action.Ctlname, methodname, args), nil
+}
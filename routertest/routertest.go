@@ -0,0 +1,191 @@
+// Package routertest : ルーティングのテストを補助するパッケージ
+package routertest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ochipin/router"
+)
+
+// update : true の場合、AssertTable は比較せずにゴールデンファイルを更新する
+// ex) go test ./... -routertest.update
+var update = flag.Bool("routertest.update", false, "update routertest golden files")
+
+// AssertRoute : パスに該当するアクションが name (Controller.Action) であり、引数が args と一致することを検証する
+// 引数は reflect.DeepEqual で比較する。期待値が文字列の場合は、fmt.Sprint で文字列化した値とも比較する
+// ex) routertest.AssertRoute(t, r, "GET", "/users/1", "Users.Show", "1")
+func AssertRoute(t testing.TB, r router.Router, method, path, name string, args ...interface{}) router.Result {
+	t.Helper()
+	action, values, err := r.Caller(method, path)
+	if err != nil {
+		t.Errorf("'[%s]: %s' - want %s, got error: %s", method, path, name, err)
+		return nil
+	}
+	if got := actionName(action); got != name {
+		t.Errorf("'[%s]: %s' - want %s, got %s", method, path, name, got)
+	}
+	if !equalArgs(values, args) {
+		t.Errorf("'[%s]: %s' - want args %s, got %s", method, path, formatArgs(args), formatValues(values))
+	}
+	return action
+}
+
+// AssertRedirect : パスがリダイレクトであり、リダイレクト先が location、ステータスコードが status であることを検証する
+// ex) routertest.AssertRedirect(t, r, "GET", "/u/1", "/users/1", http.StatusMovedPermanently)
+func AssertRedirect(t testing.TB, r router.Router, method, path, location string, status int) {
+	t.Helper()
+	action, _, err := r.Caller(method, path)
+	if err != nil {
+		t.Errorf("'[%s]: %s' - want redirect to %s, got error: %s", method, path, location, err)
+		return
+	}
+	rd, ok := action.(*router.Redirect)
+	if !ok {
+		t.Errorf("'[%s]: %s' - want redirect to %s, got %s", method, path, location, actionName(action))
+		return
+	}
+	if rd.Location != location || rd.Status != status {
+		t.Errorf("'[%s]: %s' - want redirect to %s (%d), got %s (%d)", method, path, location, status, rd.Location, rd.Status)
+	}
+}
+
+// AssertNotFound : パスに該当するアクションが存在しないことを検証する
+func AssertNotFound(t testing.TB, r router.Router, method, path string) {
+	t.Helper()
+	action, _, err := r.Caller(method, path)
+	if err == nil {
+		t.Errorf("'[%s]: %s' - want not found, got %s", method, path, actionName(action))
+		return
+	}
+	if _, ok := err.(*router.NotRoutes); !ok {
+		t.Errorf("'[%s]: %s' - want *router.NotRoutes, got %T: %s", method, path, err, err)
+	}
+}
+
+// AssertMethodNotAllowed : パスに該当するアクションが method には存在せず、他のメソッドには存在することを検証する
+func AssertMethodNotAllowed(t testing.TB, r router.Router, method, path string) {
+	t.Helper()
+	if action, _, err := r.Caller(method, path); err == nil {
+		t.Errorf("'[%s]: %s' - want method not allowed, got %s", method, path, actionName(action))
+		return
+	}
+	if allowed := Allowed(r, path); len(allowed) == 0 {
+		t.Errorf("'[%s]: %s' - want method not allowed, got not found", method, path)
+	}
+}
+
+// Allowed : パスに該当するアクションが存在するメソッドの一覧を返却する
+func Allowed(r router.Router, path string) []string {
+	var methods []string
+	for method := range r {
+		if _, _, err := r.Caller(method, path); err == nil {
+			methods = append(methods, method)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// Call : パスに該当するアクションを実行し、復帰値を返却する。失敗した場合はテストを中断する
+// ret を指定した場合は、復帰値の型を検証する
+func Call(t testing.TB, r router.Router, method, path string, ret ...string) []reflect.Value {
+	t.Helper()
+	action, args, err := r.Caller(method, path)
+	if err != nil {
+		t.Fatalf("'[%s]: %s' - %s", method, path, err)
+	}
+	out, err := action.Call(args, ret...)
+	if err != nil {
+		t.Fatalf("'[%s]: %s' - %s", method, path, err)
+	}
+	return out
+}
+
+// FormatTable : TableList の内容を、比較可能な形式の文字列へ変換する
+// 各行は種別、値をタブ区切りとし、整列して出力する
+func FormatTable(list map[string][][]string) string {
+	var lines []string
+	for kind, rows := range list {
+		for _, row := range rows {
+			lines = append(lines, kind+"\t"+strings.Join(row, "\t"))
+		}
+	}
+	sort.Strings(lines)
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// AssertTable : TableList の内容が、ゴールデンファイル golden の内容と一致することを検証する
+// -routertest.update を指定した場合は、ゴールデンファイルを更新する
+// ex) routertest.AssertTable(t, rt, "testdata/routes.golden")
+func AssertTable(t testing.TB, rt *router.RouteTable, golden string) {
+	t.Helper()
+	got := FormatTable(rt.TableList())
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%s. run go test with -routertest.update", err)
+	}
+	if got != string(want) {
+		t.Errorf("'%s' - route table mismatch\n--- want\n%s--- got\n%s", golden, want, got)
+	}
+}
+
+// actionName : アクションの Controller.Action 形式の名前を返却する
+func actionName(action router.Result) string {
+	ctlname, actname := action.Name()
+	return ctlname + "." + actname
+}
+
+// equalArgs : 抜き出した引数が期待値と一致するか判定する
+func equalArgs(values []reflect.Value, args []interface{}) bool {
+	if len(values) != len(args) {
+		return false
+	}
+	for i, v := range values {
+		got := v.Interface()
+		if reflect.DeepEqual(got, args[i]) {
+			continue
+		}
+		if s, ok := args[i].(string); ok && fmt.Sprint(got) == s {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// formatArgs : 引数の期待値を文字列へ変換する
+func formatArgs(args []interface{}) string {
+	list := make([]string, len(args))
+	for i, arg := range args {
+		list[i] = fmt.Sprintf("%#v", arg)
+	}
+	return "(" + strings.Join(list, ", ") + ")"
+}
+
+// formatValues : 抜き出した引数を文字列へ変換する
+func formatValues(values []reflect.Value) string {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v.Interface()
+	}
+	return formatArgs(args)
+}
@@ -0,0 +1,173 @@
+package routertest
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ochipin/router"
+)
+
+type Users struct{}
+
+func (c *Users) Show(id int) string { return "user" + strings.Repeat("!", id) }
+
+// fakeT : 失敗を記録する testing.TB
+type fakeT struct {
+	testing.TB
+	failed bool
+}
+
+func (t *fakeT) Helper()                                   {}
+func (t *fakeT) Errorf(format string, args ...interface{}) { t.failed = true }
+
+func table() *router.RouteTable {
+	r := router.New()
+	r.AddRegexp("name", "([a-z]+)")
+	r.Register("GET", "/users", "Users.Index")
+	r.Register("GET", "/users/:id<int>", "Users.Show")
+	r.Register("DELETE", "/users/:id<int>", "Users.Delete")
+	r.Register("GET", "/users/find/:name", "Users.Find")
+	return r
+}
+
+func Test__ROUTERTEST_ASSERT(t *testing.T) {
+	r, err := table().CreateDry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertRoute(t, r, "GET", "/users", "Users.Index")
+	AssertRoute(t, r, "GET", "/users/1", "Users.Show", "1")
+	AssertRoute(t, r, "GET", "/users/1", "Users.Show", 1)
+	AssertRoute(t, r, "GET", "/users/find/abc", "Users.Find", "abc")
+	AssertNotFound(t, r, "GET", "/posts")
+	AssertNotFound(t, r, "PUT", "/users")
+	AssertMethodNotAllowed(t, r, "POST", "/users/1")
+	if allowed := Allowed(r, "/users/1"); strings.Join(allowed, ",") != "DELETE,GET" {
+		t.Fatal("Allowed: Error", allowed)
+	}
+
+	// 検証に失敗した場合は、テストを失敗とする
+	var tests = []func(testing.TB){
+		func(t testing.TB) { AssertRoute(t, r, "GET", "/users/1", "Users.Index", "1") },
+		func(t testing.TB) { AssertRoute(t, r, "GET", "/users/1", "Users.Show", "2") },
+		func(t testing.TB) { AssertRoute(t, r, "GET", "/users/1", "Users.Show") },
+		func(t testing.TB) { AssertRoute(t, r, "GET", "/posts", "Users.Show") },
+		func(t testing.TB) { AssertNotFound(t, r, "GET", "/users") },
+		func(t testing.TB) { AssertMethodNotAllowed(t, r, "GET", "/users") },
+		func(t testing.TB) { AssertMethodNotAllowed(t, r, "POST", "/posts") },
+	}
+	for i, test := range tests {
+		ft := &fakeT{TB: t}
+		test(ft)
+		if !ft.failed {
+			t.Fatal("Assert: Error", i)
+		}
+	}
+}
+
+func Test__ROUTERTEST_REDIRECT(t *testing.T) {
+	rt := table()
+	rt.RegisterRedirect("GET", "/u/:id<int>", "/users/:id<int>", http.StatusMovedPermanently)
+	r, err := rt.CreateDry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertRedirect(t, r, "GET", "/u/1", "/users/1", http.StatusMovedPermanently)
+
+	// 検証に失敗した場合は、テストを失敗とする
+	var tests = []func(testing.TB){
+		func(t testing.TB) { AssertRedirect(t, r, "GET", "/u/1", "/users/2", http.StatusMovedPermanently) },
+		func(t testing.TB) { AssertRedirect(t, r, "GET", "/u/1", "/users/1", http.StatusFound) },
+		func(t testing.TB) { AssertRedirect(t, r, "GET", "/users/1", "/users/1", http.StatusMovedPermanently) },
+		func(t testing.TB) { AssertRedirect(t, r, "GET", "/posts", "/users/1", http.StatusMovedPermanently) },
+	}
+	for i, test := range tests {
+		ft := &fakeT{TB: t}
+		test(ft)
+		if !ft.failed {
+			t.Fatal("AssertRedirect: Error", i)
+		}
+	}
+}
+
+func Test__ROUTERTEST_CALL(t *testing.T) {
+	rt := router.New()
+	rt.AddClass(Users{})
+	rt.Register("GET", "/users/:id<int>", "Users.Show")
+	r, err := rt.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out := Call(t, r, "GET", "/users/2", "string"); out[0].String() != "user!!" {
+		t.Fatal("Call: Error", out[0].String())
+	}
+}
+
+func Test__ROUTERTEST_TABLE(t *testing.T) {
+	AssertTable(t, table(), "testdata/routes.golden")
+	if *update {
+		return
+	}
+
+	ft := &fakeT{TB: t}
+	rt := table()
+	rt.Register("GET", "/posts", "Posts.Index")
+	AssertTable(ft, rt, "testdata/routes.golden")
+	if !ft.failed {
+		t.Fatal("AssertTable: Error")
+	}
+}
+
+func Test__ROUTERTEST_RECORDER(t *testing.T) {
+	rec := NewRecorder()
+	rt := table()
+	rt.Generator = rec
+	r, err := rt.CreateDry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Return("Users.Show", "shown", nil)
+
+	out := Call(t, r, "GET", "/users/10")
+	if len(out) != 2 || out[0].String() != "shown" {
+		t.Fatal("Call: Error", out)
+	}
+	rec.AssertCalled(t, "Users.Show", 10)
+	Call(t, r, "GET", "/users/find/abc")
+	rec.AssertCalled(t, "Users.Find", "abc")
+
+	action, args, _ := r.Caller("DELETE", "/users/3")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := action.CallContext(ctx, args); err != context.Canceled {
+		t.Fatal("CallContext: Error", err)
+	}
+	if _, err := action.CallContext(context.Background(), args); err != nil {
+		t.Fatal(err)
+	}
+	elem, _ := action.Get()
+	if _, err := action.Callname(elem, "Destroy", args); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := rec.Calls()
+	if len(calls) != 4 || calls[0].Name() != "Users.Show" || calls[3].Name() != "Users.Destroy" || calls[3].Args[0] != 3 {
+		t.Fatal("Calls: Error", calls)
+	}
+	ft := &fakeT{TB: t}
+	rec.AssertCalled(ft, "Users.Show", 10)
+	if !ft.failed {
+		t.Fatal("AssertCalled: Error")
+	}
+	rec.Reset()
+	if len(rec.Calls()) != 0 {
+		t.Fatal("Reset: Error")
+	}
+	ft = &fakeT{TB: t}
+	rec.AssertCalled(ft, "Users.Show")
+	if !ft.failed {
+		t.Fatal("AssertCalled: Error")
+	}
+}
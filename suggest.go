@@ -0,0 +1,137 @@
+package router
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxSuggestions : NotRoutes.Suggestions へ設定する候補の最大数
+const maxSuggestions = 3
+
+// Suggestion : 該当するアクションが存在しない場合の、候補となるルート
+type Suggestion struct {
+	Method string
+	Path   string
+}
+
+func (s Suggestion) String() string {
+	return fmt.Sprintf("[%s]: %s", s.Method, s.Path)
+}
+
+// suggest : 該当するアクションが存在しないパスに対して、候補となるルートを返却する
+// 候補は、末尾のスラッシュの有無のみが異なるパス、メソッドのみが異なるルート、編集距離の近い固定パスの順とする
+func (r Router) suggest(method, path string, options Options) []Suggestion {
+	var list []Suggestion
+	add := func(s Suggestion) {
+		for _, v := range list {
+			if v == s {
+				return
+			}
+		}
+		if len(list) < maxSuggestions {
+			list = append(list, s)
+		}
+	}
+
+	normalized := options.normalize(path)
+	routing, ok := r[method]
+
+	// 末尾のスラッシュの有無のみが異なるパス
+	if toggled := toggleSlash(normalized); ok && toggled != "" {
+		if _, _, found := routing.match(toggled); found {
+			add(Suggestion{Method: method, Path: toggled})
+		}
+	}
+
+	// メソッドのみが異なるルート
+	var methods []string
+	for m := range r {
+		if m != method {
+			methods = append(methods, m)
+		}
+	}
+	sort.Strings(methods)
+	for _, m := range methods {
+		if _, _, found := r[m].match(normalized); found {
+			add(Suggestion{Method: m, Path: normalized})
+		}
+	}
+
+	// 編集距離の近い固定パス
+	decoded, err := unescapePath(normalized)
+	if !ok || err != nil {
+		return list
+	}
+	target := options.key(decoded)
+	limit := len([]rune(target)) / 3
+	if limit < 2 {
+		limit = 2
+	}
+	type candidate struct {
+		path     string
+		distance int
+	}
+	var candidates []candidate
+	routing.access.Walk(func(key string, _ Result) error {
+		if d := editDistance(target, key); d <= limit {
+			candidates = append(candidates, candidate{key, d})
+		}
+		return nil
+	})
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].path < candidates[j].path
+	})
+	for _, c := range candidates {
+		add(Suggestion{Method: method, Path: c.path})
+	}
+	return list
+}
+
+// suggestMessage : 候補をエラーメッセージへ付与する形式の文字列へ変換する
+func suggestMessage(list []Suggestion) string {
+	if len(list) == 0 {
+		return ""
+	}
+	names := make([]string, len(list))
+	for i, s := range list {
+		names[i] = "'" + s.String() + "'"
+	}
+	return ". did you mean " + strings.Join(names, ", ") + "?"
+}
+
+// editDistance : 2つの文字列のレーベンシュタイン距離を返却する
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	prev := make([]int, len(t)+1)
+	curr := make([]int, len(t)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		curr[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(t)]
+}
+
+// min3 : 3つの値の最小値を返却する
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,77 @@
+package router
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test__SUGGEST(t *testing.T) {
+	r := New()
+	r.AddRegexp("id", "([0-9]+)")
+	r.AddClass(Sample{})
+	r.Register("GET", "/users", "Sample.Index")
+	r.Register("GET", "/users/", "Sample.World")
+	r.Register("GET", "/posts", "Sample.Index")
+	r.Register("POST", "/users/:id", "Sample.TheTest")
+	r.Register("DELETE", "/users/:id", "Sample.TheTest")
+	r.Options.Suggest = true
+	router, err := r.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		method, path string
+		want         []Suggestion
+	}{
+		// 編集距離の近い固定パス
+		{"GET", "/usrs", []Suggestion{{"GET", "/users"}, {"GET", "/users/"}}},
+		// メソッドのみが異なるルート
+		{"GET", "/users/10", []Suggestion{{"DELETE", "/users/10"}, {"POST", "/users/10"}, {"GET", "/users/"}}},
+		{"PUT", "/users/10", []Suggestion{{"DELETE", "/users/10"}, {"POST", "/users/10"}}},
+		// 末尾のスラッシュの有無のみが異なるパス
+		{"POST", "/users/10/", []Suggestion{{"POST", "/users/10"}}},
+		// 候補が存在しない場合
+		{"GET", "/archive/2020/01", nil},
+	}
+	for _, test := range tests {
+		_, _, err := router.Caller(test.method, test.path)
+		var notRoutes *NotRoutes
+		if !errors.As(err, &notRoutes) {
+			t.Fatal("Caller: Error", test.path, err)
+		}
+		if !reflect.DeepEqual(notRoutes.Suggestions, test.want) {
+			t.Fatal("Suggestions: Error", test.path, notRoutes.Suggestions)
+		}
+		if (len(test.want) != 0) != strings.Contains(err.Error(), "did you mean") {
+			t.Fatal("Message: Error", err)
+		}
+	}
+
+	// Suggest が false の場合は、候補を設定しない
+	r.Options.Suggest = false
+	router, _ = r.Create()
+	_, _, err = router.Caller("GET", "/usrs")
+	if notRoutes := err.(*NotRoutes); notRoutes.Suggestions != nil || notRoutes.Message != "'[GET]: /usrs' - not found" {
+		t.Fatal("Suggestions: Error", notRoutes)
+	}
+}
+
+func Test__SUGGEST_DISTANCE(t *testing.T) {
+	var tests = []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"/usr/1", "/users/1", 2},
+		{"/users", "/uesrs", 2},
+		{"/ユーザ", "/ユーザー", 1},
+	}
+	for _, test := range tests {
+		if d := editDistance(test.a, test.b); d != test.want {
+			t.Fatal("editDistance: Error", test.a, test.b, d)
+		}
+	}
+}
@@ -0,0 +1,425 @@
+package router
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportTable : RouteTable を JSON 形式で入出力する際の構造体
+type exportTable struct {
+	Regexp  map[string]string `json:"regexp"`
+	Routes  []exportRoute     `json:"routes"`
+	Mounts  []exportMount     `json:"mounts,omitempty"`
+	Options *Options          `json:"options,omitempty"`
+}
+
+// exportMount : マウントした RouteTable を JSON 形式で入出力する際の構造体
+// ルートパスは、名前空間を付与したアクション名で exportTable.Routes へ出力する
+type exportMount struct {
+	Namespace string            `json:"namespace"` // 入れ子の名前空間は '.' で区切る (ex: billing.admin)
+	Regexp    map[string]string `json:"regexp"`
+}
+
+// exportRoute : ルートパスを JSON 形式で入出力する際の構造体
+type exportRoute struct {
+	Method   string                 `json:"method"`
+	Path     string                 `json:"path"`
+	Action   string                 `json:"action,omitempty"`
+	Timeout  string                 `json:"timeout,omitempty"`
+	Defaults map[string]interface{} `json:"defaults,omitempty"`
+	Meta     *Meta                  `json:"meta,omitempty"`
+	Redirect string                 `json:"redirect,omitempty"` // リダイレクト先のパス
+	Status   int                    `json:"status,omitempty"`   // リダイレクトの HTTP ステータスコード
+	Alias    string                 `json:"alias,omitempty"`    // 同じアクションを実行するルートパス
+	// マウントしたリダイレクト、別名のルートパスの名前空間
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// MarshalJSON : 登録されている正規表現、ルートパスを JSON 形式で出力する
+// ルートパスはパス、メソッドの順に整列する
+func (rt *RouteTable) MarshalJSON() ([]byte, error) {
+	var table = exportTable{Regexp: make(map[string]string), Routes: []exportRoute{}}
+	for k, v := range rt.regex {
+		table.Regexp[strings.TrimPrefix(k, ":")] = v
+	}
+	for method, routes := range rt.routes {
+		for path, route := range routes {
+			r := exportRoute{
+				Method:   method,
+				Path:     path,
+				Defaults: route.defaults,
+				Meta:     route.meta,
+				Redirect: route.redirect,
+				Status:   route.status,
+				Alias:    route.alias,
+			}
+			if route.redirect == "" && route.alias == "" {
+				r.Action = route.ctlname + "." + route.actname
+			} else {
+				r.Namespace, _ = splitNamespace(route.ctlname)
+			}
+			if route.timeout > 0 {
+				r.Timeout = route.timeout.String()
+			}
+			table.Routes = append(table.Routes, r)
+		}
+	}
+	sort.Slice(table.Routes, func(i, j int) bool {
+		a, b := table.Routes[i], table.Routes[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Method < b.Method
+	})
+	rt.eachMount(func(ns string, sub *RouteTable) {
+		m := exportMount{Namespace: ns, Regexp: make(map[string]string)}
+		for k, v := range sub.regex {
+			m.Regexp[strings.TrimPrefix(k, ":")] = v
+		}
+		table.Mounts = append(table.Mounts, m)
+	})
+	sort.Slice(table.Mounts, func(i, j int) bool {
+		return table.Mounts[i].Namespace < table.Mounts[j].Namespace
+	})
+	if rt.Options != (Options{}) {
+		table.Options = &rt.Options
+	}
+	return json.Marshal(table)
+}
+
+// UnmarshalJSON : MarshalJSON で出力した JSON 形式の正規表現、ルートパスを登録する
+// マウントした RouteTable は空の RouteTable として復元するため、コントローラは Mounted で取得して登録すること
+func (rt *RouteTable) UnmarshalJSON(data []byte) error {
+	var table exportTable
+	if err := json.Unmarshal(data, &table); err != nil {
+		return err
+	}
+	rt.init()
+	for k, v := range table.Regexp {
+		if err := rt.AddRegexp(k, v); err != nil {
+			return err
+		}
+	}
+	for _, m := range table.Mounts {
+		sub, err := rt.mountTable(m.Namespace)
+		if err != nil {
+			return err
+		}
+		for k, v := range m.Regexp {
+			if err := sub.AddRegexp(k, v); err != nil {
+				return err
+			}
+		}
+	}
+	for _, r := range table.Routes {
+		// リダイレクト、別名のルートパス
+		if r.Redirect != "" || r.Alias != "" {
+			var err error
+			if r.Redirect != "" {
+				err = rt.RegisterRedirect(r.Method, r.Path, r.Redirect, r.Status)
+			} else {
+				err = rt.RegisterAlias(r.Method, r.Path, r.Alias)
+			}
+			if err != nil {
+				return err
+			}
+			if r.Namespace != "" {
+				rt.routes[r.Method][r.Path].ctlname = r.Namespace + ":"
+			}
+			continue
+		}
+		var timeout time.Duration
+		if r.Timeout != "" {
+			t, err := time.ParseDuration(r.Timeout)
+			if err != nil || t < 0 {
+				return &InvalidArgument{Message: fmt.Sprintf("'%s' - invalid timeout. %s", r.Path, r.Timeout), Method: r.Method, Path: r.Path, Err: err}
+			}
+			timeout = t
+		}
+		// メタデータは、ルート名の重複を検証するため RegisterMeta で登録する
+		var err error
+		if r.Meta != nil {
+			err = rt.RegisterMeta(r.Method, r.Path, r.Action, *r.Meta)
+		} else {
+			err = rt.Register(r.Method, r.Path, r.Action)
+		}
+		if err != nil {
+			return err
+		}
+		if err := rt.RegisterTimeout(r.Method, r.Path, r.Action, timeout); err != nil {
+			return err
+		}
+		if err := rt.RegisterDefaults(r.Method, r.Path, r.Action, r.Defaults); err != nil {
+			return err
+		}
+	}
+	if table.Options != nil {
+		rt.Options = *table.Options
+	}
+	return nil
+}
+
+// init : 未初期化の登録用オブジェクトを生成する
+func (rt *RouteTable) init() {
+	if rt.regex == nil {
+		rt.regex = make(map[string]string)
+	}
+	if rt.classes == nil {
+		rt.classes = make(map[string]interface{})
+	}
+	if rt.factories == nil {
+		rt.factories = make(map[string]Factory)
+	}
+	if rt.routes == nil {
+		rt.routes = make(map[string]map[string]*Route)
+	}
+	if rt.mounts == nil {
+		rt.mounts = make(map[string]*RouteTable)
+	}
+	if rt.Generator == nil {
+		rt.Generator = rt
+	}
+}
+
+// Load : ルート定義を読み込み、正規表現、ルートパスを登録する
+// 先頭が '{' の場合は MarshalJSON で出力した JSON 形式、それ以外は次のテキスト形式として読み込む
+//
+//	# コメント
+//	REGEXP id ([0-9]+)
+//	GET    /users/:id  Users.Show
+//	GET    /me         Users.Show  me
+//	GET    /u/:id      REDIRECT    301  /users/:id
+//	GET    /m/:id      ALIAS       /users/:id
+//
+// ルートパスの4番目の項目には、省略可能なルート名を指定する。
+// 3番目の項目が REDIRECT の場合はリダイレクト、ALIAS の場合は別名のルートパスとして登録する
+func (rt *RouteTable) Load(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		return rt.UnmarshalJSON(trimmed)
+	}
+
+	rt.init()
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		switch {
+		case len(fields) == 5 && fields[2] == "REDIRECT":
+			status, convErr := strconv.Atoi(fields[3])
+			if convErr != nil {
+				return fmt.Errorf("line %d: '%s' - invalid redirect status", line, text)
+			}
+			err = rt.RegisterRedirect(fields[0], fields[1], fields[4], status)
+		case len(fields) == 4 && fields[2] == "ALIAS":
+			err = rt.RegisterAlias(fields[0], fields[1], fields[3])
+		case fields[0] == "REGEXP" && len(fields) == 3:
+			err = rt.AddRegexp(fields[1], fields[2])
+		case fields[0] != "REGEXP" && len(fields) == 3:
+			err = rt.Register(fields[0], fields[1], fields[2])
+		case fields[0] != "REGEXP" && len(fields) == 4:
+			err = rt.RegisterName(fields[0], fields[1], fields[2], fields[3])
+		case fields[0] == "REGEXP":
+			return fmt.Errorf("line %d: '%s' - expected 3 fields", line, text)
+		default:
+			return fmt.Errorf("line %d: '%s' - expected 3 or 4 fields", line, text)
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: %s", line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// RouteConflict : 同一のパスに複数のルートパスが一致する場合のエラー型
+type RouteConflict struct {
+	Message string
+	Method  string
+	Paths   []string
+}
+
+func (err *RouteConflict) Error() string {
+	return err.Message
+}
+
+// Is : ErrRouteConflict と比較した場合 true を返却する
+func (err *RouteConflict) Is(target error) bool {
+	return target == ErrRouteConflict
+}
+
+// Lint : 登録されているルートパスを検証し、見つかった問題を返却する
+// パスの構文エラー、未登録の正規表現の使用、同一のパスに一致するルートパスの重複、
+// 別名の参照先、リダイレクト先のパスの誤りを検出する。
+// 重複は、照合に使用するキー、正規表現が同じルートパスに加え、固定パスに一致する正規表現形式のルートパス、
+// 一方の正規表現に一致するパスの例が、他方の正規表現にも一致するルートパスを検出する
+func (rt *RouteTable) Lint() []error {
+	var errs []error
+
+	methods := make([]string, 0, len(rt.routes))
+	for method := range rt.routes {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	for _, method := range methods {
+		paths := make([]string, 0, len(rt.routes[method]))
+		for path := range rt.routes[method] {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		// 照合に使用するキー、または正規表現が同じルートパスをまとめる
+		var (
+			groups  = make(map[string][]string)
+			keys    []string
+			regexps = make(map[string]*regexp.Regexp)
+		)
+		for _, path := range paths {
+			route := rt.routes[method][path]
+			key := "static:" + rt.Options.key(path)
+			if !route.prior {
+				if err := rt.Options.normalized(method, path); err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				c, err := rt.scope(route.ctlname).compile(path)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				reg, err := rt.pathRegexp(c)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("'[%s]: %s' - %s", method, path, err))
+					continue
+				}
+				key = "regexp:" + reg.String()
+				regexps[key] = reg
+			}
+			// 別名の参照先、リダイレクト先のパスを検証する
+			if route.alias != "" {
+				if _, err := rt.resolveAlias(method, path, route); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			if route.redirect != "" {
+				if _, err := rt.redirectAction(path, route); err != nil {
+					errs = append(errs, withRoute(err, method, path))
+				}
+			}
+			if _, ok := groups[key]; !ok {
+				keys = append(keys, key)
+			}
+			groups[key] = append(groups[key], path)
+		}
+		for _, key := range keys {
+			if len(groups[key]) < 2 {
+				continue
+			}
+			errs = append(errs, &RouteConflict{
+				Message: fmt.Sprintf("'[%s]: %s' - conflicting routes", method, strings.Join(groups[key], ", ")),
+				Method:  method,
+				Paths:   groups[key],
+			})
+		}
+
+		// 照合に使用するキー、正規表現が異なり、同じパスに一致するルートパスを検出する
+		for i, a := range keys {
+			for _, b := range keys[i+1:] {
+				if !rt.overlaps(a, b, regexps) {
+					continue
+				}
+				errs = append(errs, &RouteConflict{
+					Message: fmt.Sprintf("'[%s]: %s, %s' - overlapping routes", method, groups[a][0], groups[b][0]),
+					Method:  method,
+					Paths:   []string{groups[a][0], groups[b][0]},
+				})
+			}
+		}
+	}
+	return errs
+}
+
+// overlaps : Lint でまとめたキーに該当するルートパスが、同じパスに一致するか判定する
+// 固定パスは正規表現に一致するか、正規表現同士は一方の正規表現に一致するパスの例が、他方の正規表現に一致するかで判定する
+func (rt *RouteTable) overlaps(a, b string, regexps map[string]*regexp.Regexp) bool {
+	ra, rb := regexps[a], regexps[b]
+	switch {
+	case ra == nil && rb == nil:
+		return false
+	case ra == nil:
+		return rb.MatchString(strings.TrimPrefix(a, "static:"))
+	case rb == nil:
+		return ra.MatchString(strings.TrimPrefix(b, "static:"))
+	}
+	for _, pair := range [][2]*regexp.Regexp{{ra, rb}, {rb, ra}} {
+		if example, ok := exampleOf(pair[0]); ok && pair[1].MatchString(example) {
+			return true
+		}
+	}
+	return false
+}
+
+// exampleOf : 正規表現に一致するパスの例を返却する
+// 文字クラスは英字、数字を優先し、繰り返しは最小の回数、選択は最初の候補を使用する
+func exampleOf(reg *regexp.Regexp) (string, bool) {
+	re, err := syntax.Parse(reg.String(), syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	var b strings.Builder
+	example(&b, re.Simplify())
+	return b.String(), reg.MatchString(b.String())
+}
+
+func example(b *strings.Builder, re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		b.WriteRune(classRune(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteByte('a')
+	case syntax.OpCapture, syntax.OpPlus:
+		example(b, re.Sub[0])
+	case syntax.OpRepeat:
+		for i := 0; i < re.Min; i++ {
+			example(b, re.Sub[0])
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			example(b, sub)
+		}
+	case syntax.OpAlternate:
+		example(b, re.Sub[0])
+	}
+}
+
+// classRune : 文字クラスに含まれる文字を、英字、数字、その他の順に優先して返却する
+func classRune(ranges []rune) rune {
+	for _, c := range []rune{'a', 'A', '0'} {
+		for i := 0; i+1 < len(ranges); i += 2 {
+			if ranges[i] <= c && c <= ranges[i+1] {
+				return c
+			}
+		}
+	}
+	if len(ranges) == 0 {
+		return 'a'
+	}
+	return ranges[0]
+}
@@ -0,0 +1,160 @@
+package router
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test__TABLE_LOAD(t *testing.T) {
+	r := New()
+	err := r.Load(strings.NewReader(`
+# comment
+REGEXP id ([0-9]+)
+GET    /          Sample.Index
+GET    /users/:id Sample.Hello
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.GetRegexp("id") != "([0-9]+)" || r.GetRouter("GET", "/users/:id") != "Sample.Hello" {
+		t.Fatal("Load: Error", r.TableList())
+	}
+	r.AddClass(Sample{})
+	router, err := r.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := router.Caller("GET", "/users/10"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 不正な行は、行番号付きのエラーとなる
+	for _, text := range []string{"GET /", "REGEXP id ([0-9]+", "GET / Sample"} {
+		if err := New().Load(strings.NewReader("\n" + text)); err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+			t.Fatal("Load: Error", text, err)
+		}
+	}
+}
+
+func Test__TABLE_JSON(t *testing.T) {
+	r := New()
+	r.AddRegexp("id", "([0-9]+)")
+	r.Register("GET", "/users/:id", "Sample.Hello")
+	r.RegisterTimeout("POST", "/users", "Sample.Index", time.Second)
+	r.RegisterDefaults("GET", "/archive(/:id)", "Sample.Hello", map[string]interface{}{"id": "1"})
+	r.Options.CaseInsensitive = true
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// JSON 形式で出力した内容を読み込み、同じ内容となること
+	loaded := New()
+	if err := loaded.Load(strings.NewReader(string(data))); err != nil {
+		t.Fatal(err)
+	}
+	again, err := json.Marshal(loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(again) {
+		t.Fatal("JSON: Error", string(data), string(again))
+	}
+	if !loaded.Options.CaseInsensitive || loaded.routes["POST"]["/users"].timeout != time.Second {
+		t.Fatal("JSON: Error", string(again))
+	}
+
+	var table RouteTable
+	if err := json.Unmarshal([]byte(`{"routes": [{"method": "GET", "path": "/", "action": "Sample.Index", "timeout": "x"}]}`), &table); err == nil {
+		t.Fatal("Unmarshal: Error")
+	}
+}
+
+func Test__TABLE_DRY(t *testing.T) {
+	r := New()
+	r.AddRegexp("id", "([0-9]+)")
+	r.Register("GET", "/", "Unknown.Index")
+	r.Register("GET", "/users/:id<int>/:id", "Unknown.Show")
+	// コントローラが未登録のため、Create は失敗する
+	if _, err := r.Create(); err == nil {
+		t.Fatal("Create: Error")
+	}
+	router, err := r.CreateDry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	action, args, err := router.Caller("GET", "/users/1/2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctlname, actname := action.Name(); ctlname != "Unknown" || actname != "Show" {
+		t.Fatal("Caller: Error", ctlname, actname)
+	}
+	if len(args) != 2 || args[0].Interface() != 1 || args[1].Interface() != "2" {
+		t.Fatal("Caller: Error", args)
+	}
+	// アクションは実行できない
+	if _, err := action.Call(args); err == nil {
+		t.Fatal("Call: Error")
+	}
+}
+
+func Test__TABLE_LINT(t *testing.T) {
+	r := New()
+	r.AddRegexp("id", "([0-9]+)")
+	r.Register("GET", "/users/:id", "Users.Show")
+	r.Register("GET", "/users/{id}", "Users.Detail")
+	r.Register("POST", "/users/{id}", "Users.Update")
+	r.Register("GET", "/posts/:name", "Posts.Show")
+	r.Register("GET", "/Users", "Users.Index")
+	r.Register("GET", "/users", "Users.List")
+	if errs := r.Lint(); len(errs) != 2 {
+		t.Fatal("Lint: Error", errs)
+	}
+
+	// 大文字、小文字を区別しない場合は、固定パスも重複する
+	r.Options.CaseInsensitive = true
+	errs := r.Lint()
+	if len(errs) != 3 {
+		t.Fatal("Lint: Error", errs)
+	}
+	if _, ok := errs[0].(*ParseError); !ok {
+		t.Fatal("Lint: Error", errs[0])
+	}
+	for _, err := range errs[1:] {
+		v, ok := err.(*RouteConflict)
+		if !ok || v.Method != "GET" || len(v.Paths) != 2 {
+			t.Fatal("Lint: Error", err)
+		}
+	}
+}
+
+func Test__TABLE_LINT_OVERLAP(t *testing.T) {
+	r := New()
+	r.AddRegexp("id", "([0-9]+)")
+	r.Register("GET", "/users/new", "Users.New")
+	r.Register("GET", "/users/:id", "Users.Show")
+	r.Register("GET", "/users/{name}", "Users.Find")
+	r.Register("GET", "/posts/:id<int>", "Posts.Show")
+	r.Register("GET", "/posts/{slug:[a-z]+}", "Posts.Find")
+	r.Register("POST", "/users/new", "Users.Create")
+
+	// 固定パスに一致する正規表現、同じパスに一致する正規表現を検出する
+	want := [][]string{
+		{"/users/:id", "/users/{name}"},
+		{"/users/new", "/users/{name}"},
+	}
+	errs := r.Lint()
+	if len(errs) != len(want) {
+		t.Fatal("Lint: Error", errs)
+	}
+	for i, err := range errs {
+		v, ok := err.(*RouteConflict)
+		if !ok || v.Method != "GET" || !reflect.DeepEqual(v.Paths, want[i]) {
+			t.Fatal("Lint: Error", err)
+		}
+	}
+}
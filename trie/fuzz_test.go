@@ -0,0 +1,91 @@
+package trie
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// FuzzTree : Add、Lookup、Delete を繰り返し、map と同じ結果となること、基数木の構造が保たれることを検証する
+// data は '\x00' 区切りのキー名とし、先頭バイトが奇数のキー名は削除、偶数のキー名は追加する
+func FuzzTree(f *testing.F) {
+	f.Add([]byte("name\x00name2\x00nam\x00\x01name"))
+	f.Add([]byte("/users\x00/users/new\x00/u\x00\x03/users\x00/users"))
+	f.Add([]byte("abc\x00abd\x00ab\x00\x01abc\x01abd\x00a"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		tree := new(Tree[int])
+		model := make(map[string]int)
+		for n, b := range bytes.Split(data, []byte{0}) {
+			key := string(b)
+			if len(key) > 0 && key[0]%2 == 1 {
+				key = key[1:]
+				_, exists := model[key]
+				if err := tree.Delete(key); (err == nil) != (exists && key != "") {
+					t.Fatalf("Delete(%q) - %v", key, err)
+				}
+				delete(model, key)
+			} else {
+				_, exists := model[key]
+				if err := tree.Add(key, n); (err == nil) != (!exists && key != "") {
+					t.Fatalf("Add(%q) - %v", key, err)
+				}
+				if !exists && key != "" {
+					model[key] = n
+				}
+			}
+			checkTree(t, tree, model)
+		}
+
+		// 登録済みのキー名を先頭部分とするパスは、最も長いキー名に一致する
+		for key := range model {
+			path := key + "/x"
+			prefix, _, ok := tree.LongestPrefix(path)
+			if !ok || !strings.HasPrefix(path, prefix) || len(prefix) < len(key) {
+				t.Fatalf("LongestPrefix(%q) - %q", path, prefix)
+			}
+			if _, exists := model[prefix]; !exists {
+				t.Fatalf("LongestPrefix(%q) - %q is not exists", path, prefix)
+			}
+		}
+	})
+}
+
+// checkTree : 木の内容が model と一致し、構造が保たれていることを検証する
+func checkTree(t *testing.T, tree *Tree[int], model map[string]int) {
+	t.Helper()
+	if tree.Len() != len(model) {
+		t.Fatalf("Len() = %d, want %d", tree.Len(), len(model))
+	}
+	var keys []string
+	for key, v := range model {
+		keys = append(keys, key)
+		if got, ok := tree.Lookup(key); !ok || got != v {
+			t.Fatalf("Lookup(%q) = %d, %v, want %d", key, got, ok, v)
+		}
+	}
+	sort.Strings(keys)
+	if got := tree.Keys(); strings.Join(got, "\x00") != strings.Join(keys, "\x00") {
+		t.Fatalf("Keys() = %q, want %q", got, keys)
+	}
+	checkNode(t, tree, true)
+}
+
+// checkNode : 子ノードが先頭バイトの昇順で並び、分岐のないノードが統合されていることを検証する
+func checkNode(t *testing.T, node *Tree[int], root bool) {
+	t.Helper()
+	if !root {
+		if node.prefix == "" {
+			t.Fatalf("empty prefix node")
+		}
+		if !node.endpoint && len(node.childs) < 2 {
+			t.Fatalf("%q - unmerged node with %d childs", node.prefix, len(node.childs))
+		}
+	}
+	for i, child := range node.childs {
+		if i > 0 && node.childs[i-1].prefix[0] >= child.prefix[0] {
+			t.Fatalf("%q - childs are not sorted", node.prefix)
+		}
+		checkNode(t, child, false)
+	}
+}
@@ -1,35 +1,74 @@
 package trie
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
 
-// Trie : トライ木でURLを管理する構造体
-type Trie struct {
-	childs   map[rune]*Trie // 次の要素
-	endpoint bool           // 最後尾に到達した時点で true となる
-	object   interface{}    // 登録するオブジェクト
+// Tree : 基数木(パトリシア木)でURLを管理する構造体。V は登録するオブジェクトの型
+// 分岐のない連続したノードを1つのノードへ圧縮し、バイト単位で管理する
+type Tree[V any] struct {
+	prefix   string     // このノードが表す部分文字列
+	childs   []*Tree[V] // 次の要素。prefix の先頭バイトの昇順で格納する
+	endpoint bool       // 最後尾に到達した時点で true となる
+	object   V          // 登録するオブジェクト
+	length   int        // 登録済みノード数 (ルートノードのみ使用)
+}
+
+// Trie : interface{} 型のオブジェクトを登録する基数木
+type Trie = Tree[interface{}]
+
+// child : 先頭バイトが c の子ノードの位置を返却する。存在しない場合は、挿入位置と false を返却する
+func (t *Tree[V]) child(c byte) (int, bool) {
+	idx := sort.Search(len(t.childs), func(i int) bool { return t.childs[i].prefix[0] >= c })
+	return idx, idx < len(t.childs) && t.childs[idx].prefix[0] == c
+}
+
+// commonPrefix : a と b の共通する先頭部分のバイト数を返却する
+func commonPrefix(a, b string) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
 }
 
 // Add : 新規ノードを追加する
-func (t *Trie) Add(path string, object interface{}) error {
+func (t *Tree[V]) Add(path string, object V) error {
 	// 引数のpathが空文字列の場合関数を抜ける
 	if path == "" {
 		return fmt.Errorf("path is empty")
 	}
 
-	// 次要素管理マップが作られていない場合作成する
-	if t.childs == nil {
-		t.childs = make(map[rune]*Trie)
-	}
-
 	// ノード追加処理
-	i := t
-	for _, v := range path {
-		trie, ok := i.childs[v]
+	i, key := t, path
+	for key != "" {
+		idx, ok := i.child(key[0])
+		// 先頭バイトが一致する子ノードがない場合は、残りの文字列で新規ノードを作成する
 		if !ok {
-			trie = new(Trie)
-			trie.childs = make(map[rune]*Trie)
-			i.childs[v] = trie
+			trie := &Tree[V]{prefix: key}
+			i.childs = append(i.childs, nil)
+			copy(i.childs[idx+1:], i.childs[idx:])
+			i.childs[idx] = trie
+			i = trie
+			break
+		}
+		trie := i.childs[idx]
+		l := commonPrefix(key, trie.prefix)
+		// 子ノードの途中で分岐する場合は、共通部分を新たなノードとして分割する
+		if l < len(trie.prefix) {
+			split := &Tree[V]{prefix: trie.prefix[:l], childs: []*Tree[V]{trie}}
+			trie.prefix = trie.prefix[l:]
+			i.childs[idx] = split
+			trie = split
 		}
+		key = key[l:]
 		i = trie
 	}
 
@@ -41,26 +80,209 @@ func (t *Trie) Add(path string, object interface{}) error {
 	// ノードに要素を追加する
 	i.endpoint = true
 	i.object = object
+	t.length++
 
 	return nil
 }
 
-// Get : ノードを取り出す
-func (t *Trie) Get(path string) interface{} {
-	if path == "" {
+// Get : ノードを取り出す。存在しない場合は V のゼロ値を返却する
+func (t *Tree[V]) Get(path string) V {
+	object, _ := t.Lookup(path)
+	return object
+}
+
+// Lookup : ノードを取り出す。存在しない場合は false を返却する
+func (t *Tree[V]) Lookup(path string) (V, bool) {
+	// ノード検索開始
+	i := t.find(path)
+	if i == nil || !i.endpoint {
+		var zero V
+		return zero, false
+	}
+
+	// 検索結果を返却する
+	return i.object, true
+}
+
+// Replace : 登録済みノードのオブジェクトを置き換える
+func (t *Tree[V]) Replace(path string, object V) error {
+	i := t.find(path)
+	if i == nil || !i.endpoint {
+		return fmt.Errorf("'%s' is not exists", path)
+	}
+	i.object = object
+	return nil
+}
+
+// Upsert : ノードが未登録の場合は追加、登録済みの場合はオブジェクトを置き換える
+func (t *Tree[V]) Upsert(path string, object V) error {
+	if err := t.Replace(path, object); err == nil {
 		return nil
 	}
+	return t.Add(path, object)
+}
 
-	// ノード検索開始
+// Delete : ノードを削除する。不要となった子ノードは削除し、分岐のなくなったノードは統合する
+func (t *Tree[V]) Delete(path string) error {
+	if path == "" {
+		return fmt.Errorf("path is empty")
+	}
+
+	// 削除対象ノードまでの経路を記録する
+	var nodes = []*Tree[V]{t}
+	i, key := t, path
+	for key != "" {
+		idx, ok := i.child(key[0])
+		if !ok || !strings.HasPrefix(key, i.childs[idx].prefix) {
+			return fmt.Errorf("'%s' is not exists", path)
+		}
+		i = i.childs[idx]
+		key = key[len(i.prefix):]
+		nodes = append(nodes, i)
+	}
+	if !i.endpoint {
+		return fmt.Errorf("'%s' is not exists", path)
+	}
+
+	// ノードの要素を削除する
+	var zero V
+	i.endpoint = false
+	i.object = zero
+	t.length--
+
+	parent := nodes[len(nodes)-2]
+	switch len(i.childs) {
+	case 0:
+		// 子ノードを持たない場合は、ノード自体を削除する
+		idx, _ := parent.child(i.prefix[0])
+		parent.childs = append(parent.childs[:idx], parent.childs[idx+1:]...)
+		// 親ノードの分岐がなくなった場合は、親ノードと子ノードを統合する
+		if parent != t && !parent.endpoint && len(parent.childs) == 1 {
+			parent.merge()
+		}
+	case 1:
+		// 子ノードが1つのみの場合は、子ノードと統合する
+		i.merge()
+	}
+
+	return nil
+}
+
+// merge : 唯一の子ノードを自身へ統合する
+func (t *Tree[V]) merge() {
+	child := t.childs[0]
+	t.prefix += child.prefix
+	t.childs = child.childs
+	t.endpoint = child.endpoint
+	t.object = child.object
+}
+
+// Len : 登録済みノード数を返却する
+func (t *Tree[V]) Len() int {
+	return t.length
+}
+
+// find : path に該当するノードを返却する。存在しない場合は nil を返却する
+func (t *Tree[V]) find(path string) *Tree[V] {
+	if path == "" {
+		return nil
+	}
 	i := t
-	for _, v := range path {
-		trie, ok := i.childs[v]
+	for path != "" {
+		idx, ok := i.child(path[0])
+		if !ok || !strings.HasPrefix(path, i.childs[idx].prefix) {
+			return nil
+		}
+		i = i.childs[idx]
+		path = path[len(i.prefix):]
+	}
+	return i
+}
+
+// WalkFunc : Trie.Walk でノードを順に処理する際にコールされる関数。error を返却した場合、処理を中断する
+type WalkFunc func(path string, object interface{}) error
+
+// Walk : 登録済みノードを、キー名の昇順で順に処理する
+func (t *Tree[V]) Walk(fn func(path string, object V) error) error {
+	return t.walk("", fn)
+}
+
+// WalkPrefix : prefix で始まる登録済みノードを、キー名の昇順で順に処理する
+func (t *Tree[V]) WalkPrefix(prefix string, fn func(path string, object V) error) error {
+	i, key, path := t, prefix, ""
+	for key != "" {
+		idx, ok := i.child(key[0])
 		if !ok {
 			return nil
 		}
+		trie := i.childs[idx]
+		// prefix がノードの途中で終了する場合は、そのノード以下が対象となる
+		if len(key) < len(trie.prefix) {
+			if !strings.HasPrefix(trie.prefix, key) {
+				return nil
+			}
+			return trie.walk(path, fn)
+		}
+		if !strings.HasPrefix(key, trie.prefix) {
+			return nil
+		}
+		path += trie.prefix
+		key = key[len(trie.prefix):]
 		i = trie
 	}
+	return i.walk(path[:len(path)-len(i.prefix)], fn)
+}
 
-	// 検索結果を返却する
-	return i.object
+// walk : parent に自身の prefix を連結したキー名で、自身と子ノードを順に処理する
+func (t *Tree[V]) walk(parent string, fn func(path string, object V) error) error {
+	path := parent + t.prefix
+	if t.endpoint {
+		if err := fn(path, t.object); err != nil {
+			return err
+		}
+	}
+	// 子ノードは先頭バイトの昇順で格納されているため、そのまま処理する
+	for _, child := range t.childs {
+		if err := child.walk(path, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Keys : 登録済みのキー名を昇順で返却する
+func (t *Tree[V]) Keys() []string {
+	return t.WithPrefix("")
+}
+
+// WithPrefix : prefix で始まる登録済みのキー名を昇順で返却する
+func (t *Tree[V]) WithPrefix(prefix string) []string {
+	var keys = []string{}
+	t.WalkPrefix(prefix, func(path string, _ V) error {
+		keys = append(keys, path)
+		return nil
+	})
+	return keys
+}
+
+// LongestPrefix : path の先頭部分に一致する登録済みノードのうち、最も長いキー名とオブジェクトを返却する
+func (t *Tree[V]) LongestPrefix(path string) (string, V, bool) {
+	var (
+		key    string
+		object V
+		found  bool
+	)
+	i, n := t, 0
+	for n < len(path) {
+		idx, ok := i.child(path[n])
+		if !ok || !strings.HasPrefix(path[n:], i.childs[idx].prefix) {
+			break
+		}
+		i = i.childs[idx]
+		n += len(i.prefix)
+		if i.endpoint {
+			key, object, found = path[:n], i.object, true
+		}
+	}
+	return key, object, found
 }
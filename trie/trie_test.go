@@ -43,3 +43,322 @@ func Test__TRIEY(t *testing.T) {
 		t.Fatal("trie.Get: fatal")
 	}
 }
+
+func Test__TRIE_DELETE(t *testing.T) {
+	trie := new(Trie)
+	trie.Add("/users", 1)
+	trie.Add("/users/new", 2)
+	trie.Add("/posts", 3)
+	if trie.Len() != 3 {
+		t.Fatal("trie.Len: fatal")
+	}
+
+	// 存在しないキー名、途中までのキー名を指定した場合はエラーとなる
+	if err := trie.Delete(""); err == nil {
+		t.Fatal("trie.Delete: fatal")
+	}
+	if err := trie.Delete("/comments"); err == nil {
+		t.Fatal("trie.Delete: fatal")
+	}
+	if err := trie.Delete("/user"); err == nil {
+		t.Fatal("trie.Delete: fatal")
+	}
+
+	// 子ノードを持つノードを削除した場合、子ノードは残る
+	if err := trie.Delete("/users"); err != nil {
+		t.Fatal(err)
+	}
+	if v := trie.Get("/users"); v != nil {
+		t.Fatal("trie.Get: fatal")
+	}
+	if v := trie.Get("/users/new"); fmt.Sprint(v) != "2" {
+		t.Fatal("trie.Get: fatal")
+	}
+
+	// 末端のノードを削除した場合、不要となったノードも削除される
+	if err := trie.Delete("/users/new"); err != nil {
+		t.Fatal(err)
+	}
+	if len(trie.childs) != 1 || trie.childs[0].prefix != "/posts" {
+		t.Fatal("trie.Delete: not pruned")
+	}
+	if trie.Len() != 1 {
+		t.Fatal("trie.Len: fatal")
+	}
+
+	// 削除後、再度登録可能
+	if err := trie.Add("/users", 4); err != nil {
+		t.Fatal(err)
+	}
+	if trie.Len() != 2 {
+		t.Fatal("trie.Len: fatal")
+	}
+}
+
+func Test__TRIE_REPLACE(t *testing.T) {
+	trie := new(Trie)
+	trie.Add("name", 100)
+
+	// 存在しないキー名を置き換えた場合はエラーとなる
+	if err := trie.Replace("nam", 200); err == nil {
+		t.Fatal("trie.Replace: fatal")
+	}
+	if err := trie.Replace("name", 200); err != nil {
+		t.Fatal(err)
+	}
+	if v := trie.Get("name"); fmt.Sprint(v) != "200" {
+		t.Fatal("trie.Get: fatal")
+	}
+
+	// 未登録の場合は追加、登録済みの場合は置き換える
+	if err := trie.Upsert("age", 20); err != nil {
+		t.Fatal(err)
+	}
+	if err := trie.Upsert("name", 300); err != nil {
+		t.Fatal(err)
+	}
+	if err := trie.Upsert("", 300); err == nil {
+		t.Fatal("trie.Upsert: fatal")
+	}
+	if v := trie.Get("name"); fmt.Sprint(v) != "300" || trie.Len() != 2 {
+		t.Fatal("trie.Upsert: fatal")
+	}
+	if v := trie.Get("age"); fmt.Sprint(v) != "20" {
+		t.Fatal("trie.Get: fatal")
+	}
+}
+
+func Test__TRIE_WALK(t *testing.T) {
+	trie := new(Trie)
+	for i, v := range []string{"/users/new", "/posts", "/users", "/users/1", "/"} {
+		trie.Add(v, i)
+	}
+
+	// 昇順でキー名を取得できるか検証する
+	if v := fmt.Sprint(trie.Keys()); v != "[/ /posts /users /users/1 /users/new]" {
+		t.Fatal("trie.Keys: fatal", v)
+	}
+	if v := fmt.Sprint(trie.WithPrefix("/users/")); v != "[/users/1 /users/new]" {
+		t.Fatal("trie.WithPrefix: fatal", v)
+	}
+	if v := trie.WithPrefix("/comments"); len(v) != 0 {
+		t.Fatal("trie.WithPrefix: fatal", v)
+	}
+	if v := fmt.Sprint(new(Trie).Keys()); v != "[]" {
+		t.Fatal("trie.Keys: fatal", v)
+	}
+
+	// error を返却した場合、処理が中断されるか検証する
+	var n int
+	err := trie.Walk(func(path string, object interface{}) error {
+		n++
+		if path == "/users" {
+			return fmt.Errorf("stop")
+		}
+		return nil
+	})
+	if err == nil || n != 3 {
+		t.Fatal("trie.Walk: fatal")
+	}
+}
+
+func Test__TRIE_LONGESTPREFIX(t *testing.T) {
+	trie := new(Trie)
+	trie.Add("/static", 1)
+	trie.Add("/static/css", 2)
+	trie.Add("/日本", 3)
+
+	var tests = []struct {
+		path, key, object string
+		found             bool
+	}{
+		{"/static/css/main.css", "/static/css", "2", true},
+		{"/static/js/main.js", "/static", "1", true},
+		{"/static", "/static", "1", true},
+		{"/stat", "", "<nil>", false},
+		{"/日本語", "/日本", "3", true},
+		{"", "", "<nil>", false},
+	}
+	for _, test := range tests {
+		key, object, found := trie.LongestPrefix(test.path)
+		if key != test.key || fmt.Sprint(object) != test.object || found != test.found {
+			t.Fatal("trie.LongestPrefix: fatal", test.path, key, object, found)
+		}
+	}
+}
+
+func Test__TRIE_RADIX(t *testing.T) {
+	trie := new(Trie)
+	trie.Add("/users/new", 1)
+	trie.Add("/users/1", 2)
+	trie.Add("/users", 3)
+
+	// 共通部分でノードが分割されるか検証する
+	if len(trie.childs) != 1 || trie.childs[0].prefix != "/users" {
+		t.Fatal("trie.Add: not split")
+	}
+	users := trie.childs[0]
+	if len(users.childs) != 1 || users.childs[0].prefix != "/" || len(users.childs[0].childs) != 2 {
+		t.Fatal("trie.Add: not split")
+	}
+	for k, v := range map[string]string{"/users/new": "1", "/users/1": "2", "/users": "3", "/users/": "<nil>", "/user": "<nil>"} {
+		if fmt.Sprint(trie.Get(k)) != v {
+			t.Fatal("trie.Get: fatal", k)
+		}
+	}
+
+	// 分岐のなくなったノードが統合されるか検証する
+	trie.Delete("/users/1")
+	if len(users.childs) != 1 || users.childs[0].prefix != "/new" {
+		t.Fatal("trie.Delete: not merged")
+	}
+	trie.Delete("/users")
+	if len(trie.childs) != 1 || trie.childs[0].prefix != "/users/new" {
+		t.Fatal("trie.Delete: not merged")
+	}
+	if v := trie.Get("/users/new"); fmt.Sprint(v) != "1" {
+		t.Fatal("trie.Get: fatal")
+	}
+}
+
+// runeTrie : 比較用の、1文字ごとにノードを生成するトライ木
+type runeTrie struct {
+	childs   map[rune]*runeTrie
+	endpoint bool
+	object   interface{}
+}
+
+func (t *runeTrie) Add(path string, object interface{}) error {
+	if path == "" {
+		return fmt.Errorf("path is empty")
+	}
+	if t.childs == nil {
+		t.childs = make(map[rune]*runeTrie)
+	}
+	i := t
+	for _, v := range path {
+		trie, ok := i.childs[v]
+		if !ok {
+			trie = &runeTrie{childs: make(map[rune]*runeTrie)}
+			i.childs[v] = trie
+		}
+		i = trie
+	}
+	if i.endpoint {
+		return fmt.Errorf("'%s' is already exists", path)
+	}
+	i.endpoint = true
+	i.object = object
+	return nil
+}
+
+func (t *runeTrie) Get(path string) interface{} {
+	if path == "" {
+		return nil
+	}
+	i := t
+	for _, v := range path {
+		trie, ok := i.childs[v]
+		if !ok {
+			return nil
+		}
+		i = trie
+	}
+	return i.object
+}
+
+// benchPaths : ベンチマーク用の固定パス
+func benchPaths() []string {
+	var paths []string
+	for _, r := range []string{"users", "posts", "comments", "articles", "categories"} {
+		for i := 0; i < 400; i++ {
+			paths = append(paths, fmt.Sprintf("/api/v1/%s/item%d/detail", r, i))
+		}
+	}
+	return paths
+}
+
+func Benchmark__TRIE_ADD(b *testing.B) {
+	paths := benchPaths()
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		trie := new(Trie)
+		for _, p := range paths {
+			trie.Add(p, p)
+		}
+	}
+}
+
+func Benchmark__RUNETRIE_ADD(b *testing.B) {
+	paths := benchPaths()
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		trie := new(runeTrie)
+		for _, p := range paths {
+			trie.Add(p, p)
+		}
+	}
+}
+
+func Benchmark__TRIE_GET(b *testing.B) {
+	paths := benchPaths()
+	trie := new(Trie)
+	for _, p := range paths {
+		trie.Add(p, p)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		trie.Get(paths[n%len(paths)])
+	}
+}
+
+func Benchmark__RUNETRIE_GET(b *testing.B) {
+	paths := benchPaths()
+	trie := new(runeTrie)
+	for _, p := range paths {
+		trie.Add(p, p)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		trie.Get(paths[n%len(paths)])
+	}
+}
+
+func Test__TRIE_TYPED(t *testing.T) {
+	// 型パラメータを指定して生成する
+	tree := new(Tree[int])
+	tree.Add("/users", 1)
+	tree.Add("/users/new", 0)
+
+	// 登録されているゼロ値と、未登録を区別できるか検証する
+	if v, ok := tree.Lookup("/users/new"); v != 0 || !ok {
+		t.Fatal("tree.Lookup: fatal")
+	}
+	if v, ok := tree.Lookup("/users/"); v != 0 || ok {
+		t.Fatal("tree.Lookup: fatal")
+	}
+	if v := tree.Get("/users"); v != 1 {
+		t.Fatal("tree.Get: fatal")
+	}
+	if key, v, ok := tree.LongestPrefix("/users/1"); key != "/users" || v != 1 || !ok {
+		t.Fatal("tree.LongestPrefix: fatal")
+	}
+	var sum int
+	tree.Walk(func(path string, object int) error {
+		sum += object
+		return nil
+	})
+	if sum != 1 {
+		t.Fatal("tree.Walk: fatal")
+	}
+
+	// Trie は interface{} 型のオブジェクトを登録する
+	var trie *Trie = new(Tree[interface{}])
+	trie.Add("name", "value")
+	var fn WalkFunc = func(path string, object interface{}) error { return nil }
+	if err := trie.Walk(fn); err != nil {
+		t.Fatal(err)
+	}
+}